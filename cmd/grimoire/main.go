@@ -17,8 +17,13 @@ import (
 
 var version = "dev"
 
+// errUnmatchedFilters is returned by --validate when filter patterns match nothing.
+var errUnmatchedFilters = errors.New("filter patterns match nothing")
+
 // errConfigConflict is returned when --config is combined with other flags.
-var errConfigConflict = errors.New("--config cannot be combined with --source, --no-builtin, or filter flags")
+var errConfigConflict = errors.New(
+	"--config cannot be combined with --source, --no-builtin, --agent-concurrency, or filter flags",
+)
 
 type stringSlice []string
 
@@ -34,6 +39,7 @@ func (s *stringSlice) Set(value string) error {
 
 type flags struct {
 	showVersion bool
+	validate    bool
 	verbose     bool
 	configFile  string
 	sourcePaths stringSlice
@@ -42,6 +48,7 @@ type flags struct {
 	blockRules  stringSlice
 	allowSkills stringSlice
 	blockSkills stringSlice
+	concurrency int
 }
 
 func main() {
@@ -68,6 +75,10 @@ func run() error {
 		return err
 	}
 
+	if f.validate {
+		return runValidate(cfg)
+	}
+
 	slog.Info("starting grimoire", slog.String("version", version))
 
 	return runServer(cfg)
@@ -77,14 +88,16 @@ func parseFlags() *flags {
 	f := &flags{}
 
 	flag.BoolVar(&f.showVersion, "version", false, "Show version")
+	flag.BoolVar(&f.validate, "validate", false, "Validate configuration and sources, then exit")
 	flag.BoolVar(&f.verbose, "verbose", false, "Enable verbose logging (debug level)")
 	flag.StringVar(&f.configFile, "config", "", "Load configuration from YAML file")
 	flag.Var(&f.sourcePaths, "source", "External source directory (can be repeated)")
 	flag.BoolVar(&f.noBuiltin, "no-builtin", false, "Disable embedded/builtin content")
-	flag.Var(&f.allowRules, "allow-rule", "Only load these rules (can be repeated)")
-	flag.Var(&f.blockRules, "block-rule", "Block these rules (can be repeated)")
-	flag.Var(&f.allowSkills, "allow-skill", "Only load these skills (can be repeated)")
-	flag.Var(&f.blockSkills, "block-skill", "Block these skills (can be repeated)")
+	flag.Var(&f.allowRules, "allow-rule", "Only load rules matching this name or pattern (can be repeated)")
+	flag.Var(&f.blockRules, "block-rule", "Block rules matching this name or pattern (can be repeated)")
+	flag.Var(&f.allowSkills, "allow-skill", "Only load skills matching this name or pattern (can be repeated)")
+	flag.Var(&f.blockSkills, "block-skill", "Block skills matching this name or pattern (can be repeated)")
+	flag.IntVar(&f.concurrency, "agent-concurrency", 0, "Maximum number of agents run at once (default 4)")
 
 	flag.Parse()
 
@@ -99,7 +112,12 @@ func runServer(cfg *grimoire.Config) error {
 
 	slog.Debug("store initialized")
 
-	srv := mcp.New(version, store)
+	warnUnmatchedFilters(store)
+
+	srv := mcp.New(version, store, cfg)
+
+	// Forward logs to connected clients in addition to stderr
+	slog.SetDefault(slog.New(srv.LogHandler(slog.Default().Handler())))
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -120,12 +138,41 @@ func runServer(cfg *grimoire.Config) error {
 	return nil
 }
 
+// runValidate loads the sources with cfg and reports filter patterns that match nothing.
+func runValidate(cfg *grimoire.Config) error {
+	store, err := grimoire.New(cfg, sources.FS)
+	if err != nil {
+		return fmt.Errorf("loading sources: %w", err)
+	}
+
+	unmatched := warnUnmatchedFilters(store)
+	if unmatched > 0 {
+		return fmt.Errorf("%w: %d", errUnmatchedFilters, unmatched)
+	}
+
+	_, _ = fmt.Fprintln(os.Stdout, "configuration and sources are valid")
+
+	return nil
+}
+
+// warnUnmatchedFilters logs each filter pattern that matches nothing and returns their count.
+func warnUnmatchedFilters(store *grimoire.Store) int {
+	unmatched := store.UnmatchedFilters()
+
+	for _, u := range unmatched {
+		slog.Warn("filter pattern matches nothing", slog.String("list", u.List), slog.String("pattern", u.Pattern))
+	}
+
+	return len(unmatched)
+}
+
 // buildConfig creates a Config from either a config file or CLI flags.
 // Config file and CLI flags are mutually exclusive.
 func buildConfig(f *flags) (*grimoire.Config, error) {
 	hasCLIFlags := len(f.sourcePaths) > 0 || f.noBuiltin ||
 		len(f.allowRules) > 0 || len(f.blockRules) > 0 ||
-		len(f.allowSkills) > 0 || len(f.blockSkills) > 0
+		len(f.allowSkills) > 0 || len(f.blockSkills) > 0 ||
+		f.concurrency != 0
 
 	if f.configFile != "" && hasCLIFlags {
 		return nil, errConfigConflict
@@ -154,6 +201,7 @@ func buildConfig(f *flags) (*grimoire.Config, error) {
 	cfg.Rules.Block = f.blockRules
 	cfg.Skills.Allow = f.allowSkills
 	cfg.Skills.Block = f.blockSkills
+	cfg.Execution.Concurrency = f.concurrency
 
 	err := cfg.Validate()
 	if err != nil {
@@ -0,0 +1,305 @@
+package grimoire
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// ArgumentType is the type of an argument value.
+type ArgumentType string
+
+const (
+	ArgumentString  ArgumentType = "string"
+	ArgumentEnum    ArgumentType = "enum"
+	ArgumentInteger ArgumentType = "integer"
+	ArgumentBoolean ArgumentType = "boolean"
+	ArgumentList    ArgumentType = "list"
+	ArgumentPath    ArgumentType = "path"
+)
+
+func (t ArgumentType) Valid() bool {
+	switch t {
+	case ArgumentString, ArgumentEnum, ArgumentInteger, ArgumentBoolean, ArgumentList, ArgumentPath:
+		return true
+	default:
+		return false
+	}
+}
+
+type Argument struct {
+	Name        string `yaml:"name"        toml:"name"`
+	Description string `yaml:"description" toml:"description"`
+	Required    bool   `yaml:"required"    toml:"required"`
+
+	// Type is the value type. Default: string.
+	// Lists are comma- or newline-separated; paths must be relative and stay
+	// within the directory they are relative to.
+	Type ArgumentType `yaml:"type" toml:"type"`
+
+	// Values lists the allowed values. Required for enums; for lists, it
+	// restricts each item.
+	Values []string `yaml:"values" toml:"values"`
+
+	// Pattern is a regular expression that string and path values, and list
+	// items, must match in full.
+	Pattern string `yaml:"pattern" toml:"pattern"`
+
+	// Default is used when no value is given.
+	Default string `yaml:"default" toml:"default"`
+
+	// Complete declares where completion values for this argument come from.
+	Complete *Completion `yaml:"complete" toml:"complete"`
+
+	// pattern is the compiled Pattern, set by validate.
+	pattern *regexp.Regexp
+}
+
+// Completion describes the source of completion values for an argument.
+// Sources can be combined; values from all of them are offered.
+type Completion struct {
+	// Values lists static values (e.g., an enum of focus areas).
+	Values []string `yaml:"values" toml:"values"`
+
+	// Entries completes with the names of entries of the given type.
+	Entries Type `yaml:"entries" toml:"entries"`
+
+	// Paths completes with file paths under the client's roots.
+	Paths bool `yaml:"paths" toml:"paths"`
+}
+
+// ValueType returns the argument's type, defaulting to string.
+func (a *Argument) ValueType() ArgumentType {
+	if a.Type == "" {
+		return ArgumentString
+	}
+
+	return a.Type
+}
+
+// validate checks the argument declaration and compiles its pattern.
+func (a *Argument) validate() error {
+	err := validateArgumentName(a.Name)
+	if err != nil {
+		return err
+	}
+
+	typ := a.ValueType()
+
+	switch {
+	case !typ.Valid():
+		return fmt.Errorf("argument %q: %w: unknown type %q", a.Name, ErrInvalidArgument, a.Type)
+	case typ == ArgumentEnum && len(a.Values) == 0:
+		return fmt.Errorf("argument %q: %w: enum requires values", a.Name, ErrInvalidArgument)
+	case len(a.Values) > 0 && (typ == ArgumentInteger || typ == ArgumentBoolean || typ == ArgumentPath):
+		return fmt.Errorf("argument %q: %w: %s does not accept values", a.Name, ErrInvalidArgument, typ)
+	case a.Pattern != "" && typ != ArgumentString && typ != ArgumentList && typ != ArgumentPath:
+		return fmt.Errorf("argument %q: %w: %s does not accept a pattern", a.Name, ErrInvalidArgument, typ)
+	}
+
+	if a.Pattern != "" {
+		pattern, err := regexp.Compile(`^(?:` + a.Pattern + `)$`)
+		if err != nil {
+			return fmt.Errorf("argument %q: %w: pattern: %w", a.Name, ErrInvalidArgument, err)
+		}
+
+		a.pattern = pattern
+	}
+
+	if a.Complete != nil && a.Complete.Entries != "" && !a.Complete.Entries.Valid() {
+		return fmt.Errorf("argument %q: completion entries: %w: %q", a.Name, ErrInvalidType, a.Complete.Entries)
+	}
+
+	if a.Default != "" {
+		err := a.ValidateValue(a.Default)
+		if err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateValue checks a non-empty value against the argument's type,
+// allowed values and pattern.
+func (a *Argument) ValidateValue(value string) error {
+	switch a.ValueType() {
+	case ArgumentInteger:
+		_, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("argument %q: %w: %q is not an integer", a.Name, ErrInvalidArgumentValue, value)
+		}
+	case ArgumentBoolean:
+		_, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("argument %q: %w: %q is not a boolean", a.Name, ErrInvalidArgumentValue, value)
+		}
+	case ArgumentList:
+		for _, item := range splitList(value) {
+			err := a.validateText(item)
+			if err != nil {
+				return err
+			}
+		}
+	case ArgumentPath:
+		if !filepath.IsLocal(filepath.FromSlash(value)) {
+			return fmt.Errorf("argument %q: %w: %q must be a relative path within the project", a.Name, ErrInvalidArgumentValue, value)
+		}
+
+		return a.validateText(value)
+	default:
+		return a.validateText(value)
+	}
+
+	return nil
+}
+
+// validateText checks a string value, or a list item, against the allowed values and pattern.
+func (a *Argument) validateText(value string) error {
+	if len(a.Values) > 0 && !slices.Contains(a.Values, value) {
+		return fmt.Errorf("argument %q: %w: %q is not one of %s",
+			a.Name, ErrInvalidArgumentValue, value, strings.Join(a.Values, ", "))
+	}
+
+	if a.pattern != nil && !a.pattern.MatchString(value) {
+		return fmt.Errorf("argument %q: %w: %q does not match %s", a.Name, ErrInvalidArgumentValue, value, a.Pattern)
+	}
+
+	return nil
+}
+
+// NeedsValue reports whether a value must be given for the argument: it is
+// required and has no default to fall back to.
+func (a *Argument) NeedsValue() bool {
+	return a.Required && a.Default == ""
+}
+
+// Choices returns the values a client can offer for the argument: the allowed
+// values of enums, strings and list items, or true and false for booleans.
+func (a *Argument) Choices() []string {
+	switch a.ValueType() {
+	case ArgumentBoolean:
+		return []string{"true", "false"}
+	case ArgumentString, ArgumentEnum, ArgumentList:
+		return a.Values
+	default:
+		return nil
+	}
+}
+
+// Hint describes the argument's type and constraints for clients,
+// e.g. "integer; default: 3", or "" for unconstrained strings.
+func (a *Argument) Hint() string {
+	var parts []string
+
+	typ := a.ValueType()
+
+	switch {
+	case typ == ArgumentList && len(a.Values) > 0:
+		parts = append(parts, "comma-separated list of: "+strings.Join(a.Values, ", "))
+	case typ == ArgumentList:
+		parts = append(parts, "comma-separated list")
+	case len(a.Values) > 0:
+		parts = append(parts, "one of: "+strings.Join(a.Values, ", "))
+	case typ != ArgumentString:
+		parts = append(parts, string(typ))
+	}
+
+	if a.Pattern != "" {
+		parts = append(parts, "pattern: "+a.Pattern)
+	}
+
+	if a.Default != "" {
+		parts = append(parts, "default: "+a.Default)
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// JSONSchema returns a schema for the argument's value. Only primitive types
+// are used, so lists are described as strings.
+func (a *Argument) JSONSchema() *jsonschema.Schema {
+	schema := &jsonschema.Schema{Type: "string", Title: a.Name, Description: a.Description}
+
+	switch a.ValueType() {
+	case ArgumentInteger:
+		schema.Type = "integer"
+	case ArgumentBoolean:
+		schema.Type = "boolean"
+	case ArgumentString, ArgumentEnum:
+		for _, v := range a.Values {
+			schema.Enum = append(schema.Enum, v)
+		}
+
+		if a.pattern != nil {
+			schema.Pattern = a.pattern.String()
+		}
+	case ArgumentPath:
+		if a.pattern != nil {
+			schema.Pattern = a.pattern.String()
+		}
+	case ArgumentList:
+		schema.Description = strings.TrimSpace(schema.Description + " (" + a.Hint() + ")")
+	}
+
+	return schema
+}
+
+// typedValue converts a value for use in templates: booleans become bool,
+// integers int, lists []string, and other types stay strings.
+// Empty values become the zero value of the type.
+func (a *Argument) typedValue(value string) (any, error) {
+	switch a.ValueType() {
+	case ArgumentBoolean:
+		if value == "" {
+			return false, nil
+		}
+
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w: %q is not a boolean", a.Name, ErrInvalidArgumentValue, value)
+		}
+
+		return b, nil
+	case ArgumentInteger:
+		if value == "" {
+			return 0, nil
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w: %q is not an integer", a.Name, ErrInvalidArgumentValue, value)
+		}
+
+		return n, nil
+	case ArgumentList:
+		return splitList(value), nil
+	default:
+		return value, nil
+	}
+}
+
+// ValidateArguments checks the given non-empty values against the declared
+// arguments. Values for undeclared arguments are ignored.
+func (e *Entry) ValidateArguments(values map[string]string) error {
+	for i := range e.Arguments {
+		arg := &e.Arguments[i]
+
+		value := values[arg.Name]
+		if value == "" {
+			continue
+		}
+
+		err := arg.ValidateValue(value)
+		if err != nil {
+			return fmt.Errorf("%s %q: %w", e.Type, e.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+package grimoire
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// bundleSuffix precedes the extension of bundle files, e.g. "go.bundle.md".
+const bundleSuffix = ".bundle"
+
+// parsedEntry is an entry read from a source file.
+type parsedEntry struct {
+	entry *Entry
+
+	// name is the entry's name within a bundle file, or "" for single-entry files.
+	name string
+
+	// line is the line of the file the entry starts on.
+	line int
+}
+
+// bundleEntry is an entry in a bundle file, named within the bundle.
+type bundleEntry struct {
+	Name  string `yaml:"name" toml:"name"`
+	Entry `yaml:",inline"`
+
+	// Body is the entry's body in YAML bundles.
+	Body string `yaml:"body" toml:"body"`
+}
+
+// parseFile parses the entries in a source file: a markdown file with a single
+// entry, a markdown bundle (".bundle.md") whose entries' frontmatter starts with
+// their name, or a YAML bundle (".bundle.yaml") listing entries with their
+// bodies. Errors start with the path, and the line of the failing entry for bundles.
+func parseFile(filePath string, data []byte) ([]parsedEntry, error) {
+	switch {
+	case path.Ext(filePath) != ".md":
+		return parseYAMLBundle(filePath, data)
+	case isBundleFile(filePath):
+		return parseMarkdownBundle(filePath, strings.SplitAfter(string(data), "\n"))
+	}
+
+	entry, err := parseMarkdown(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+
+	return []parsedEntry{{entry: entry, line: 1}}, nil
+}
+
+// isSourceFile reports whether the file at path can contain entries: a
+// markdown file or a YAML bundle. Other YAML files are ignored.
+func isSourceFile(filePath string) bool {
+	switch path.Ext(filePath) {
+	case ".md":
+		return true
+	case ".yaml", ".yml":
+		return isBundleFile(filePath)
+	}
+
+	return false
+}
+
+// isBundleFile reports whether the file at path is named as a bundle, with
+// ".bundle" before its extension.
+func isBundleFile(filePath string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(filePath, path.Ext(filePath)), bundleSuffix)
+}
+
+// parseMarkdownBundle parses a markdown file with several entries, each starting
+// with YAML, TOML or JSON frontmatter whose first field is its name. Frontmatter
+// delimiters in fenced code blocks are ignored.
+func parseMarkdownBundle(filePath string, lines []string) ([]parsedEntry, error) {
+	if bundleFormat(lines, 0) == nil {
+		return nil, fmt.Errorf("%s: %w: must start with frontmatter whose first field is \"name\"",
+			filePath, ErrInvalidBundle)
+	}
+
+	var (
+		starts  []int
+		formats []*frontmatterFormat
+		fence   string
+
+		// closing is the delimiter closing the current frontmatter, if any.
+		closing string
+	)
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case closing != "":
+			if trimmed == closing {
+				closing = ""
+			}
+		case fence == "" && bundleFormat(lines, i) != nil:
+			format := bundleFormat(lines, i)
+			starts = append(starts, i)
+			formats = append(formats, format)
+			closing = format.delimiter
+		case fence == "" && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")):
+			fence = trimmed[:3]
+		case fence != "" && strings.HasPrefix(trimmed, fence):
+			fence = ""
+		}
+	}
+
+	entries := make([]parsedEntry, len(starts))
+
+	for i, start := range starts {
+		end := len(lines)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+
+		parsed, err := parseBundleChunk(filePath, formats[i], lines, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		entries[i] = parsed
+	}
+
+	return entries, nil
+}
+
+// parseBundleChunk parses the markdown bundle entry on lines [start, end).
+// The frontmatter is padded to its position in the file, so decoding errors
+// report lines of the file.
+func parseBundleChunk(filePath string, format *frontmatterFormat, lines []string, start, end int) (parsedEntry, error) {
+	chunk := []byte(strings.Join(lines[start:end], ""))
+
+	frontmatter, body, err := splitFrontmatter(chunk, format.delimiter)
+	if err != nil {
+		return parsedEntry{}, fmt.Errorf("%s:%d: %w", filePath, start+1, err)
+	}
+
+	frontmatter = append([]byte(strings.Repeat("\n", start)), frontmatter...)
+
+	parsed, err := decodeBundleEntry(filePath, start+1, func(b *bundleEntry) error {
+		return format.decode(frontmatter, b)
+	})
+	if err != nil {
+		return parsedEntry{}, err
+	}
+
+	parsed.entry.Body = strings.TrimRight(string(body), "\n") + "\n"
+
+	return parsed, nil
+}
+
+// parseYAMLBundle parses a YAML file holding a list of entries with their
+// frontmatter fields, a name and a body.
+func parseYAMLBundle(filePath string, data []byte) ([]parsedEntry, error) {
+	var nodes []yaml.Node
+
+	err := yaml.Unmarshal(data, &nodes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: must be a list of entries: %w", filePath, ErrInvalidBundle, err)
+	}
+
+	entries := make([]parsedEntry, len(nodes))
+
+	for i := range nodes {
+		parsed, err := decodeBundleEntry(filePath, nodes[i].Line, func(b *bundleEntry) error {
+			return nodes[i].Decode(b)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		entries[i] = parsed
+	}
+
+	return entries, nil
+}
+
+// decodeBundleEntry decodes the bundle entry starting at line and checks its name.
+func decodeBundleEntry(filePath string, line int, decode func(*bundleEntry) error) (parsedEntry, error) {
+	var b bundleEntry
+
+	err := decode(&b)
+	if err != nil {
+		return parsedEntry{}, fmt.Errorf("%s:%d: parsing frontmatter: %w", filePath, line, err)
+	}
+
+	if !fs.ValidPath(b.Name) || b.Name == "." {
+		return parsedEntry{}, fmt.Errorf("%s:%d: %w: invalid entry name %q", filePath, line, ErrInvalidBundle, b.Name)
+	}
+
+	entry := &b.Entry
+	entry.Body = b.Body
+
+	return parsedEntry{entry: entry, name: b.Name, line: line}, nil
+}
+
+// bundleFormat returns the format of the frontmatter opening at line i if it
+// opens an entry in a markdown bundle, with the entry's name as its first
+// field, or nil otherwise.
+func bundleFormat(lines []string, i int) *frontmatterFormat {
+	if i+1 >= len(lines) {
+		return nil
+	}
+
+	// JSON frontmatter may have its opening brace on a line of its own.
+	next := strings.Join(lines[i+1:min(i+3, len(lines))], "")
+
+	for j := range frontmatterFormats {
+		format := &frontmatterFormats[j]
+
+		if strings.TrimSpace(lines[i]) == format.delimiter && format.bundleName.MatchString(next) {
+			return format
+		}
+	}
+
+	return nil
+}
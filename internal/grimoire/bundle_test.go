@@ -0,0 +1,232 @@
+package grimoire
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIsSourceFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"rules/errors.md", true},
+		{"rules/go.bundle.md", true},
+		{"rules/go.bundle.yaml", true},
+		{"rules/go.bundle.yml", true},
+		{"rules/go.yaml", false},
+		{".config.yaml", false},
+		{"rules/notes.txt", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			got := isSourceFile(tt.path)
+			if got != tt.want {
+				t.Errorf("isSourceFile(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	t.Parallel()
+
+	type want struct {
+		name string
+		line int
+		body string
+	}
+
+	tests := []struct {
+		name string
+		path string
+		data string
+		want []want
+	}{
+		{
+			name: "single entry",
+			path: "rules/errors.md",
+			data: "---\ntype: rule\ndescription: Errors\n---\nWrap errors.\n",
+			want: []want{{"", 1, "Wrap errors.\n"}},
+		},
+		{
+			name: "single entry with name field",
+			path: "skills/deploy.md",
+			data: "---\nname: deploy\ntype: skill\ndescription: Deploy\n---\nShip it.\n",
+			want: []want{{"", 1, "Ship it.\n"}},
+		},
+		{
+			name: "markdown bundle",
+			path: "rules/go.bundle.md",
+			data: "---\nname: no-panics\ntype: rule\ndescription: Panics\n---\nReturn errors.\n\n" +
+				"---\nname: wrap\ntype: rule\ndescription: Wrap\n---\nWrap with %w.\n",
+			want: []want{{"no-panics", 1, "Return errors.\n"}, {"wrap", 8, "Wrap with %w.\n"}},
+		},
+		{
+			name: "markdown bundle keeps other delimiters in bodies",
+			path: "rules/go.bundle.md",
+			data: "---\nname: a\ntype: rule\ndescription: A\n---\nAbove.\n\n---\n\nBelow.\n\n" +
+				"```\n---\nname: fake\n---\n```\n",
+			want: []want{{"a", 1, "Above.\n\n---\n\nBelow.\n\n```\n---\nname: fake\n---\n```\n"}},
+		},
+		{
+			name: "markdown bundle mixing frontmatter formats",
+			path: "rules/go.bundle.md",
+			data: "+++\nname = \"t\"\ntype = \"rule\"\n+++\nTOML.\n" +
+				";;;\n{\n  \"name\": \"j\",\n  \"type\": \"rule\"\n}\n;;;\nJSON.\n" +
+				";;;\n{\"name\": \"k\", \"type\": \"rule\"}\n;;;\nInline JSON.\n" +
+				"---\nname: y\ntype: rule\n---\nYAML.\n",
+			want: []want{{"t", 1, "TOML.\n"}, {"j", 6, "JSON.\n"}, {"k", 13, "Inline JSON.\n"}, {"y", 17, "YAML.\n"}},
+		},
+		{
+			name: "YAML bundle",
+			path: "rules/misc.bundle.yaml",
+			data: "- name: one\n  type: rule\n  description: One\n  body: |\n    First.\n" +
+				"- name: two\n  type: rule\n  description: Two\n  body: Second.\n",
+			want: []want{{"one", 1, "First.\n"}, {"two", 6, "Second."}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			parsed, err := parseFile(tt.path, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("parseFile() error = %v", err)
+			}
+
+			if len(parsed) != len(tt.want) {
+				t.Fatalf("parseFile() returned %d entries, want %d", len(parsed), len(tt.want))
+			}
+
+			for i, w := range tt.want {
+				p := parsed[i]
+				if p.name != w.name || p.line != w.line || p.entry.Body != w.body {
+					t.Errorf("entry %d = {%q, %d, %q}, want {%q, %d, %q}",
+						i, p.name, p.line, p.entry.Body, w.name, w.line, w.body)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFileErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		path string
+		data string
+		want error
+
+		// prefix is the start of the error message, naming the file and line.
+		prefix string
+	}{
+		{
+			name:   "bundle without name header",
+			path:   "rules/go.bundle.md",
+			data:   "---\ntype: rule\n---\nBody.\n",
+			want:   ErrInvalidBundle,
+			prefix: "rules/go.bundle.md: ",
+		},
+		{
+			name:   "invalid entry name",
+			path:   "rules/go.bundle.md",
+			data:   "---\nname: a\ntype: rule\n---\nA.\n---\nname: ../b\ntype: rule\n---\nB.\n",
+			want:   ErrInvalidBundle,
+			prefix: "rules/go.bundle.md:6: ",
+		},
+		{
+			name:   "unclosed frontmatter",
+			path:   "rules/go.bundle.md",
+			data:   "---\nname: a\ntype: rule\n",
+			want:   ErrInvalidFrontmatter,
+			prefix: "rules/go.bundle.md:1: ",
+		},
+		{
+			name:   "invalid field in second entry",
+			path:   "rules/go.bundle.md",
+			data:   "---\nname: a\ntype: rule\n---\nA.\n---\nname: b\nglobs: [\n---\nB.\n",
+			prefix: "rules/go.bundle.md:6: parsing frontmatter: yaml: line 8: ",
+		},
+		{
+			name:   "invalid TOML field in second entry",
+			path:   "rules/go.bundle.md",
+			data:   "---\nname: a\ntype: rule\n---\nA.\n+++\nname = \"b\"\nglobs = \"x\"\n+++\nB.\n",
+			prefix: "rules/go.bundle.md:6: parsing frontmatter: toml: line 8 ",
+		},
+		{
+			name:   "invalid JSON in second entry",
+			path:   "rules/go.bundle.md",
+			data:   "---\nname: a\ntype: rule\n---\nA.\n;;;\n{\n  \"name\": \"b\",\n  \"globs\": [\n}\n;;;\nB.\n",
+			prefix: "rules/go.bundle.md:6: parsing frontmatter: json: line 10: ",
+		},
+		{
+			name:   "YAML bundle that is not a list",
+			path:   "rules/go.bundle.yaml",
+			data:   "name: a\n",
+			want:   ErrInvalidBundle,
+			prefix: "rules/go.bundle.yaml: ",
+		},
+		{
+			name:   "invalid field in YAML bundle",
+			path:   "rules/go.bundle.yml",
+			data:   "- name: a\n  type: rule\n- name: b\n  globs: x\n",
+			prefix: "rules/go.bundle.yml:3: parsing frontmatter: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseFile(tt.path, []byte(tt.data))
+			if err == nil {
+				t.Fatal("parseFile() error = nil")
+			}
+
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("parseFile() error = %v, want %v", err, tt.want)
+			}
+
+			if !strings.HasPrefix(err.Error(), tt.prefix) {
+				t.Errorf("parseFile() error = %q, want prefix %q", err, tt.prefix)
+			}
+		})
+	}
+}
+
+func TestDeriveName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		typ  Type
+		want string
+	}{
+		{"rules/go/error-assignment.md", TypeRule, "go/error-assignment"},
+		{"skills/refactor.md", TypeSkill, "refactor"},
+		{"custom/go/my-rule.md", TypeRule, "custom/go/my-rule"},
+		{"rules/go.bundle.yaml", TypeRule, "go"},
+		{"rules/go/basics.bundle.md", TypeRule, "go/basics"},
+		{"skills/notes.bundle.md", TypeRule, "skills/notes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			got := deriveName(tt.path, tt.typ)
+			if got != tt.want {
+				t.Errorf("deriveName(%q, %q) = %q, want %q", tt.path, tt.typ, got, tt.want)
+			}
+		})
+	}
+}
@@ -3,20 +3,26 @@ package grimoire
 import (
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
 	Sources      SourcesConfig `yaml:"sources"`
-	Rules        FilterConfig  `yaml:"rules"`
+	Rules        RulesConfig   `yaml:"rules"`
 	Skills       FilterConfig  `yaml:"skills"`
 	Instructions FilterConfig  `yaml:"instructions"`
 	Agents       FilterConfig  `yaml:"agents"`
+
+	Execution ExecutionConfig `yaml:"execution"`
 }
 
 type SourcesConfig struct {
@@ -28,14 +34,73 @@ type SourcesConfig struct {
 	Paths []string `yaml:"paths"`
 }
 
+const (
+	// DefaultConcurrency is the number of agents run at once when not configured.
+	DefaultConcurrency = 4
+
+	// DefaultTimeout is the per-agent time limit when neither config nor frontmatter set one.
+	DefaultTimeout = 5 * time.Minute
+
+	// DefaultRetries is the number of retries after a transient agent failure.
+	DefaultRetries = 1
+)
+
+type ExecutionConfig struct {
+	// Concurrency limits how many agents the agent tool runs at once. Default: 4.
+	Concurrency int `yaml:"concurrency"`
+
+	// Timeout limits each agent attempt. Agents can override it in frontmatter. Default: 5m.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Retries is the number of retries after a transient failure. Default: 1.
+	Retries *int `yaml:"retries"`
+
+	// Local configures an OpenAI-compatible endpoint that runs agents when the
+	// client cannot sample, or when an agent sets "executor: local".
+	Local *LocalConfig `yaml:"local"`
+}
+
+type LocalConfig struct {
+	// URL is the base URL of the chat completions API (e.g., "http://localhost:11434/v1").
+	URL string `yaml:"url"`
+
+	// Model is the model name sent with every request.
+	Model string `yaml:"model"`
+
+	// APIKeyEnv names the environment variable holding the API key. Optional.
+	APIKeyEnv string `yaml:"api_key_env"`
+}
+
+// FilterConfig selects the entries of a type to load. Names in Allow and Block
+// can be exact names, globs ("go/*", "slog-*") or regular expressions prefixed
+// with "re:" (e.g., "re:go/slog-.*").
 type FilterConfig struct {
-	// Allow lists names to allow. If non-empty, only these are loaded.
+	// Allow lists names to allow. If Allow or AllowTags is non-empty, only
+	// entries matching either are loaded.
 	Allow []string `yaml:"allow"`
 
-	// Block lists names to block. Ignored if Allow is non-empty.
+	// AllowTags lists tags to allow: entries with any of them are loaded.
+	AllowTags []string `yaml:"allow_tags"`
+
+	// Block lists names to block, taking precedence over the allow lists.
 	Block []string `yaml:"block"`
+
+	// BlockTags lists tags to block: entries with any of them are skipped.
+	BlockTags []string `yaml:"block_tags"`
 }
 
+type RulesConfig struct {
+	FilterConfig `yaml:",inline"`
+
+	// Severity overrides the severity of rules by name or pattern, as in the
+	// filter lists (e.g., {"todos": "must", "go/*": "may"}). Exact names take
+	// precedence over patterns, and longer patterns over shorter ones.
+	Severity map[string]Severity `yaml:"severity"`
+}
+
+// regexPrefix marks a filter pattern as a regular expression.
+const regexPrefix = "re:"
+
 func (c *Config) BuiltinEnabled() bool {
 	if c.Sources.Builtin == nil {
 		return true
@@ -44,6 +109,38 @@ func (c *Config) BuiltinEnabled() bool {
 	return *c.Sources.Builtin
 }
 
+// ConcurrencyLimit returns the configured agent concurrency, or DefaultConcurrency if unset.
+func (e *ExecutionConfig) ConcurrencyLimit() int {
+	if e.Concurrency == 0 {
+		return DefaultConcurrency
+	}
+
+	return e.Concurrency
+}
+
+// TimeoutFor returns the time limit for an agent attempt: the agent's own
+// timeout if set, otherwise the configured timeout, otherwise DefaultTimeout.
+func (e *ExecutionConfig) TimeoutFor(agent *Entry) time.Duration {
+	if agent.Timeout > 0 {
+		return agent.Timeout
+	}
+
+	if e.Timeout > 0 {
+		return e.Timeout
+	}
+
+	return DefaultTimeout
+}
+
+// RetryLimit returns the configured number of retries, or DefaultRetries if unset.
+func (e *ExecutionConfig) RetryLimit() int {
+	if e.Retries == nil {
+		return DefaultRetries
+	}
+
+	return *e.Retries
+}
+
 func DefaultConfig() *Config {
 	return &Config{}
 }
@@ -98,12 +195,139 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	err = c.Execution.Validate()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (e *ExecutionConfig) Validate() error {
+	if e.Concurrency < 0 {
+		return fmt.Errorf("execution: concurrency %d: %w", e.Concurrency, ErrInvalidConcurrency)
+	}
+
+	if e.Timeout < 0 {
+		return fmt.Errorf("execution: timeout %s: %w", e.Timeout, ErrInvalidTimeout)
+	}
+
+	if e.Retries != nil && *e.Retries < 0 {
+		return fmt.Errorf("execution: retries %d: %w", *e.Retries, ErrInvalidRetries)
+	}
+
+	if e.Local != nil {
+		err := e.Local.Validate()
+		if err != nil {
+			return fmt.Errorf("execution: local: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (l *LocalConfig) Validate() error {
+	u, err := url.Parse(l.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url %q must be an http(s) URL", ErrInvalidExecutor, l.URL)
+	}
+
+	if l.Model == "" {
+		return fmt.Errorf("%w: model is required", ErrInvalidExecutor)
+	}
+
 	return nil
 }
 
 func (f *FilterConfig) Validate(name string) error {
-	if len(f.Allow) > 0 && len(f.Block) > 0 {
-		return fmt.Errorf("%s: %w", name, ErrFilterConflict)
+	for _, pattern := range f.Allow {
+		err := validatePattern(pattern)
+		if err != nil {
+			return fmt.Errorf("%s: allow: %w", name, err)
+		}
+	}
+
+	for _, pattern := range f.Block {
+		err := validatePattern(pattern)
+		if err != nil {
+			return fmt.Errorf("%s: block: %w", name, err)
+		}
+	}
+
+	for _, tag := range f.AllowTags {
+		if !tagPattern.MatchString(tag) {
+			return fmt.Errorf("%s: allow_tags: %w: %q must be lowercase letters, digits and dashes", name, ErrInvalidTag, tag)
+		}
+	}
+
+	for _, tag := range f.BlockTags {
+		if !tagPattern.MatchString(tag) {
+			return fmt.Errorf("%s: block_tags: %w: %q must be lowercase letters, digits and dashes", name, ErrInvalidTag, tag)
+		}
+	}
+
+	return nil
+}
+
+func (r *RulesConfig) Validate(name string) error {
+	err := r.FilterConfig.Validate(name)
+	if err != nil {
+		return err
+	}
+
+	for pattern, severity := range r.Severity {
+		err := validatePattern(pattern)
+		if err != nil {
+			return fmt.Errorf("%s: severity: %w", name, err)
+		}
+
+		if !severity.Valid() {
+			return fmt.Errorf("%s: severity %q: %w: %q must be must, should or may", name, pattern, ErrInvalidSeverity, severity)
+		}
+	}
+
+	return nil
+}
+
+// SeverityFor returns the configured severity for the named rule, or "" if
+// no override matches it.
+func (r *RulesConfig) SeverityFor(name string) Severity {
+	if severity, ok := r.Severity[name]; ok {
+		return severity
+	}
+
+	var best string
+
+	for pattern := range r.Severity {
+		if !matchesName(pattern, name) {
+			continue
+		}
+
+		if best == "" || len(pattern) > len(best) || len(pattern) == len(best) && pattern < best {
+			best = pattern
+		}
+	}
+
+	if best == "" {
+		return ""
+	}
+
+	return r.Severity[best]
+}
+
+func validatePattern(pattern string) error {
+	if expr, ok := strings.CutPrefix(pattern, regexPrefix); ok {
+		_, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("%w %q: %w", ErrInvalidFilter, pattern, err)
+		}
+
+		return nil
+	}
+
+	_, err := path.Match(pattern, "")
+	if err != nil {
+		return fmt.Errorf("%w %q: %w", ErrInvalidFilter, pattern, err)
 	}
 
 	return nil
@@ -126,20 +350,56 @@ func ExpandHome(path string) string {
 	return filepath.Join(home, path[1:])
 }
 
-func (f *FilterConfig) IsAllowed(name string) bool {
-	// If allow list is set, only those names are allowed
-	if len(f.Allow) > 0 {
-		return slices.Contains(f.Allow, name)
+// IsAllowed reports whether the filter admits entry, by name or by tag.
+// Entries must match the allow lists, if any are set, and not the block lists.
+func (f *FilterConfig) IsAllowed(entry *Entry) bool {
+	allowed := len(f.Allow) == 0 && len(f.AllowTags) == 0 ||
+		matchesAnyName(f.Allow, entry.Name) || hasAnyTag(entry, f.AllowTags)
+
+	return allowed && !matchesAnyName(f.Block, entry.Name) && !hasAnyTag(entry, f.BlockTags)
+}
+
+// matchesName reports whether a filter pattern matches an entry name. Patterns
+// are exact names, globs, or regular expressions prefixed with "re:", and must
+// match the whole name. Globs without a slash also match the last element of
+// the name, so "slog-*" matches "go/slog-use".
+func matchesName(pattern, name string) bool {
+	if expr, ok := strings.CutPrefix(pattern, regexPrefix); ok {
+		matched, err := regexp.MatchString(`^(?:`+expr+`)$`, name)
+
+		return err == nil && matched
 	}
 
-	// Otherwise, check block list
-	return !slices.Contains(f.Block, name)
+	matched, err := path.Match(pattern, name)
+	if err == nil && matched {
+		return true
+	}
+
+	if !strings.ContainsAny(pattern, "*?[") || strings.Contains(pattern, "/") {
+		return false
+	}
+
+	matched, err = path.Match(pattern, path.Base(name))
+
+	return err == nil && matched
+}
+
+func matchesAnyName(patterns []string, name string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		return matchesName(pattern, name)
+	})
+}
+
+func hasAnyTag(entry *Entry, tags []string) bool {
+	return slices.ContainsFunc(tags, func(tag string) bool {
+		return slices.Contains(entry.Tags, tag)
+	})
 }
 
 func (c *Config) FilterForType(typ Type) *FilterConfig {
 	switch typ {
 	case TypeRule:
-		return &c.Rules
+		return &c.Rules.FilterConfig
 	case TypeSkill:
 		return &c.Skills
 	case TypeInstruction:
@@ -0,0 +1,112 @@
+package grimoire
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMatchesName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"todos", "todos", true},
+		{"todos", "x/todos", false},
+		{"go/*", "go/errors", true},
+		{"go/*", "go/a/b", false},
+		{"go/*", "rust/errors", false},
+		{"slog-*", "go/slog-use", true},
+		{"slog-*", "slog-use", true},
+		{"go/slog-?se", "go/slog-use", true},
+		{"*/errors", "go/errors", true},
+		{"*/errors", "errors", false},
+		{"[a-c]x", "bx", true},
+		{"re:.*-return", "go/naked-return", true},
+		{"re:go/slog-(use|context)", "go/slog-context", true},
+		{"re:go/slog", "go/slog-use", false},
+		{"re:slog", "go/slog", false},
+		{"re:(", "(", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := matchesName(tt.pattern, tt.name)
+			if got != tt.want {
+				t.Errorf("matchesName(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeverityFor(t *testing.T) {
+	t.Parallel()
+
+	rules := RulesConfig{
+		Severity: map[string]Severity{
+			"todos":        SeverityMust,
+			"go/*":         SeverityMay,
+			"go/slog-*":    SeverityShould,
+			"go/slog-use":  SeverityMust,
+			"re:.*-return": SeverityMust,
+			"a/x*":         SeverityShould,
+			"a/*x":         SeverityMay,
+		},
+	}
+
+	tests := []struct {
+		name string
+		want Severity
+	}{
+		{"todos", SeverityMust},
+		{"go/errors", SeverityMay},
+		{"go/slog-context", SeverityShould}, // longer pattern wins
+		{"go/slog-use", SeverityMust},       // exact name wins
+		{"go/naked-return", SeverityMust},   // regular expressions compete by length
+		{"rust/early-return", SeverityMust},
+		{"a/xx", SeverityMay}, // equal lengths: lexically smaller pattern wins
+		{"python/imports", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := rules.SeverityFor(tt.name)
+			if got != tt.want {
+				t.Errorf("SeverityFor(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		filter FilterConfig
+		want   error
+	}{
+		{"valid", FilterConfig{Allow: []string{"go/*", "re:.*"}, AllowTags: []string{"security"}}, nil},
+		{"invalid glob", FilterConfig{Block: []string{"go/["}}, ErrInvalidFilter},
+		{"invalid regexp", FilterConfig{Allow: []string{"re:("}}, ErrInvalidFilter},
+		{"invalid allow tag", FilterConfig{AllowTags: []string{"Security"}}, ErrInvalidTag},
+		{"invalid block tag", FilterConfig{BlockTags: []string{"no spaces"}}, ErrInvalidTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.filter.Validate("rules")
+			if !errors.Is(err, tt.want) || (tt.want == nil) != (err == nil) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
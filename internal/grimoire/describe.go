@@ -3,59 +3,172 @@ package grimoire
 import (
 	"cmp"
 	"fmt"
+	"log/slog"
 	"slices"
 	"strings"
 )
 
 // BuildGuidanceDescription generates the guidance tool description.
 // Includes available skills and rules so the tool is self-documenting.
+// Deprecated entries are left out.
 func BuildGuidanceDescription(s *Store) string {
 	var b strings.Builder
 
 	b.WriteString("Load guidance by name.\n\n")
 	b.WriteString("USAGE:\n")
 	b.WriteString("- guidance(name: \"rule-name\") - Load one\n")
-	b.WriteString("- guidance(names: [\"a\", \"b\"]) - Load multiple")
+	b.WriteString("- guidance(names: [\"a\", \"b\"]) - Load multiple\n")
+	b.WriteString("- guidance(name: \"skill-name\", arguments: {\"arg\": \"value\"}) - Fill in skill arguments")
 
-	skills := s.List(TypeSkill)
+	skills := s.ListCurrent(TypeSkill)
 	if len(skills) > 0 {
 		b.WriteString("\n\nSKILLS - Load with guidance(name) BEFORE these tasks:\n")
 
-		for _, e := range skills {
-			summary := summarizeDescription(e.Description)
-			fmt.Fprintf(&b, "- %s: %s\n", e.Name, summary)
-		}
+		writeByTag(&b, skills, func(e *Entry) string {
+			return fmt.Sprintf("- %s%s: %s\n", e.Name, e.FormatAgents(), summarizeDescription(e.Description))
+		})
 	}
 
-	rules := s.List(TypeRule)
+	rules := SortBySeverity(s.ListCurrent(TypeRule))
 	if len(rules) > 0 {
-		b.WriteString("\nRULES - Apply based on description, load with guidance() if you need examples:\n")
+		b.WriteString("\nRULES - Apply based on description, load with guidance() if you need examples.\n")
+		b.WriteString("[must] rules are required, [should] rules are strong defaults, [may] rules are optional:\n")
 
-		for _, e := range rules {
-			fmt.Fprintf(&b, "- %s%s: %s\n", e.Name, e.FormatGlobs(), e.Description)
+		writeByTag(&b, rules, func(e *Entry) string {
+			return fmt.Sprintf("- %s%s%s: %s\n", e.Name, e.FormatSeverity(), e.FormatGlobs(), e.Description)
+		})
+	}
+
+	tags := s.Tags()
+	if len(tags) > 0 {
+		counts := make([]string, len(tags))
+		for i, t := range tags {
+			counts[i] = fmt.Sprintf("%s (%d)", t.Tag, t.Count)
 		}
+
+		b.WriteString("\nTAGS - Filter search() and suggest() with tags: [...]:\n")
+		b.WriteString(strings.Join(counts, ", ") + "\n")
 	}
 
 	return b.String()
 }
 
+// writeByTag writes a line per entry, grouped under their first tag.
+// Untagged entries come first, without a heading.
+func writeByTag(b *strings.Builder, entries []*Entry, line func(*Entry) string) {
+	groups := make(map[string][]*Entry)
+
+	var tags []string
+
+	for _, e := range entries {
+		var tag string
+		if len(e.Tags) > 0 {
+			tag = e.Tags[0]
+		}
+
+		if _, ok := groups[tag]; !ok && tag != "" {
+			tags = append(tags, tag)
+		}
+
+		groups[tag] = append(groups[tag], e)
+	}
+
+	slices.Sort(tags)
+
+	for _, e := range groups[""] {
+		b.WriteString(line(e))
+	}
+
+	for _, tag := range tags {
+		fmt.Fprintf(b, "[%s]\n", tag)
+
+		for _, e := range groups[tag] {
+			b.WriteString(line(e))
+		}
+	}
+}
+
 func BuildAgentDescription(s *Store) string {
 	var b strings.Builder
 
-	b.WriteString("Execute agent prompts via MCP sampling. Requires client sampling support.\n")
+	b.WriteString("Execute agent prompts via MCP sampling, or on the local executor if one is configured.\n\n")
+	b.WriteString("USAGE:\n")
+	b.WriteString("- agent(names: [\"a\", \"b\"], context: \"...\") - Run agents\n")
+	b.WriteString("- agent(skill: \"skill-name\", context: \"...\") - Run a skill's agents with the skill as context\n")
+	b.WriteString("- agent(names: [\"a\"], arguments: {\"a\": {\"arg\": \"value\"}})")
+	b.WriteString(" - Pass agent arguments (* = required)\n")
 
-	agents := s.List(TypeAgent)
+	agents := s.ListCurrent(TypeAgent)
 	if len(agents) > 0 {
 		b.WriteString("\nAGENTS:\n")
 
 		for _, e := range agents {
-			fmt.Fprintf(&b, "- %s: %s\n", e.Name, e.Description)
+			fmt.Fprintf(&b, "- %s%s%s: %s\n", e.Name, e.FormatSteps(), e.FormatArguments(), e.Description)
+		}
+	}
+
+	var delegating []*Entry
+
+	for _, e := range s.ListCurrent(TypeSkill) {
+		if len(e.Agents) > 0 {
+			delegating = append(delegating, e)
 		}
 	}
 
+	if len(delegating) > 0 {
+		b.WriteString("\nSKILLS WITH AGENTS:\n")
+
+		for _, e := range delegating {
+			fmt.Fprintf(&b, "- %s: %s\n", e.Name, strings.Join(e.Agents, ", "))
+		}
+	}
+
+	return b.String()
+}
+
+// BuildDelegationSection lists the agents a skill delegates to, for appending
+// to the skill's rendered body. Returns "" if the skill has no agents.
+func BuildDelegationSection(s *Store, skill *Entry) string {
+	if len(skill.Agents) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+
+	b.WriteString("\n\n## Agents\n\n")
+	fmt.Fprintf(&b, "Delegate specialized analysis with agent(skill: %q) or agent(names: [...]):\n", skill.Name)
+
+	for _, name := range skill.Agents {
+		agent, err := s.Get(TypeAgent, name)
+		if err != nil {
+			continue
+		}
+
+		fmt.Fprintf(&b, "- %s: %s\n", agent.Name, agent.Description)
+	}
+
 	return b.String()
 }
 
+// BuildPromptDescription returns the prompt description for a skill,
+// mentioning the agents it delegates to and its replacement if deprecated.
+func BuildPromptDescription(skill *Entry) string {
+	desc := skill.Description
+
+	switch {
+	case skill.Deprecated && skill.ReplacedBy != "":
+		desc = fmt.Sprintf("Deprecated, use %s instead: %s", skill.ReplacedBy, desc)
+	case skill.Deprecated:
+		desc = "Deprecated: " + desc
+	}
+
+	if len(skill.Agents) == 0 {
+		return desc
+	}
+
+	return strings.TrimSpace(desc) + "\n\nDelegates to agents: " + strings.Join(skill.Agents, ", ")
+}
+
 // summarizeDescription returns a short summary of the description.
 // Uses the first sentence or line, truncated if too long.
 func summarizeDescription(desc string) string {
@@ -88,7 +201,7 @@ func BuildServerInstructions(s *Store) string {
 
 	b.WriteString("Grimoire provides project-specific coding guidance.\n")
 
-	instructions := s.List(TypeInstruction)
+	instructions := s.ListCurrent(TypeInstruction)
 	if len(instructions) > 0 {
 		slices.SortFunc(instructions, func(a, b *Entry) int {
 			if a.Order != b.Order {
@@ -105,7 +218,14 @@ func BuildServerInstructions(s *Store) string {
 				b.WriteString("\n")
 			}
 
-			b.WriteString(instr.Body)
+			body, err := instr.RenderBody(nil)
+			if err != nil {
+				slog.Warn("instruction render failed", slog.String("name", instr.Name), slog.Any("error", err))
+
+				body = instr.Body
+			}
+
+			b.WriteString(body)
 		}
 	}
 
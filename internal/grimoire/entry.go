@@ -1,9 +1,17 @@
 package grimoire
 
 import (
+	"cmp"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
 )
 
 type Type string
@@ -15,6 +23,59 @@ const (
 	TypeAgent       Type = "agent"
 )
 
+// tagPattern matches valid tags, e.g. "security" or "go-errors".
+var tagPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// MaxTurnsLimit is the highest max_turns an agent may set.
+const MaxTurnsLimit = 20
+
+// Executor selects the backend that runs an agent.
+type Executor string
+
+const (
+	// ExecutorSampling runs the agent through MCP sampling on the client.
+	ExecutorSampling Executor = "sampling"
+
+	// ExecutorLocal runs the agent on the configured OpenAI-compatible endpoint.
+	ExecutorLocal Executor = "local"
+)
+
+func (x Executor) Valid() bool {
+	return x == ExecutorSampling || x == ExecutorLocal
+}
+
+// Severity is how strictly a rule must be followed.
+type Severity string
+
+const (
+	// SeverityMust marks rules that are required.
+	SeverityMust Severity = "must"
+
+	// SeverityShould marks rules that are strong defaults. Rules without a severity have this one.
+	SeverityShould Severity = "should"
+
+	// SeverityMay marks optional rules.
+	SeverityMay Severity = "may"
+)
+
+func (v Severity) Valid() bool {
+	return slices.Contains(severityOrder, v)
+}
+
+// severityOrder lists the severities from most to least strict.
+var severityOrder = []Severity{SeverityMust, SeverityShould, SeverityMay}
+
+// Rank orders severities from most to least strict, for sorting.
+// Empty severities, as of entries other than rules, rank last.
+func (v Severity) Rank() int {
+	rank := slices.Index(severityOrder, v)
+	if rank < 0 {
+		return len(severityOrder)
+	}
+
+	return rank
+}
+
 func (t Type) Valid() bool {
 	switch t {
 	case TypeRule, TypeSkill, TypeInstruction, TypeAgent:
@@ -24,35 +85,125 @@ func (t Type) Valid() bool {
 	}
 }
 
-type Argument struct {
-	Name        string `yaml:"name"`
-	Description string `yaml:"description"`
-	Required    bool   `yaml:"required"`
+// Step is one stage of an agent pipeline.
+type Step struct {
+	// Agents lists the agents run in parallel during this step.
+	Agents []string `yaml:"agents" toml:"agents"`
 }
 
 type Entry struct {
-	Name string `yaml:"-"`
-	Type Type   `yaml:"type"`
+	Name string `yaml:"-"    toml:"-"`
+	Type Type   `yaml:"type" toml:"type"`
+
+	// Extends names an entry of the same type to inherit from (e.g., "code-review").
+	// Unset fields are inherited and the body is merged by markdown heading.
+	// An entry may extend the entry of the same name that it shadows.
+	Extends string `yaml:"extends" toml:"extends"`
 
 	// Description explains what this entry does and when to use it.
 	// For skills, this should be detailed (up to 1024 chars) to help agents
 	// understand when to activate the skill. Follows Agent Skills spec.
-	Description string `yaml:"description"`
+	Description string `yaml:"description" toml:"description"`
 
 	// Globs are file patterns that trigger this entry (e.g., "*.go").
 	// Used primarily by rules.
-	Globs []string `yaml:"globs"`
+	Globs []string `yaml:"globs" toml:"globs"`
+
+	// Tags group entries by topic (e.g., "security") for filtering and search.
+	// The first tag is the entry's primary category.
+	Tags []string `yaml:"tags" toml:"tags"`
+
+	// Aliases are other names the entry can be loaded by, e.g. its names before a rename.
+	Aliases []string `yaml:"aliases" toml:"aliases"`
+
+	// Deprecated hides the entry from tool descriptions, search and suggestions.
+	// It can still be loaded, with a deprecation notice prepended.
+	Deprecated bool `yaml:"deprecated" toml:"deprecated"`
+
+	// DeprecationMessage explains why the entry is deprecated.
+	DeprecationMessage string `yaml:"deprecation_message" toml:"deprecation_message"`
+
+	// ReplacedBy names the entry of the same type that replaces a deprecated entry.
+	ReplacedBy string `yaml:"replaced_by" toml:"replaced_by"`
+
+	// Severity is how strictly a rule must be followed: must, should or may.
+	// Rules default to should; projects can override it in config.
+	Severity Severity `yaml:"severity" toml:"severity"`
 
 	// Order controls the injection order for instructions (lower = earlier).
-	Order int `yaml:"order"`
+	Order int `yaml:"order" toml:"order"`
 
-	// Arguments defines parameters that skills can accept for templating.
-	Arguments []Argument `yaml:"arguments"`
+	// Arguments defines parameters that skills and agents accept for templating.
+	Arguments []Argument `yaml:"arguments" toml:"arguments"`
 
 	// Agents references agent names that this skill can delegate to.
-	Agents []string `yaml:"agents"`
+	Agents []string `yaml:"agents" toml:"agents"`
+
+	// Sampling configures model parameters for agents.
+	Sampling *Sampling `yaml:"sampling" toml:"sampling"`
 
-	Body string `yaml:"-"`
+	// Steps turns an agent into a pipeline: each step runs its agents in parallel,
+	// and their outputs become context for the next step.
+	Steps []Step `yaml:"steps" toml:"steps"`
+
+	// OutputSchema is a JSON schema the agent's response must match.
+	// When set, the response is parsed and validated as JSON.
+	OutputSchema map[string]any `yaml:"output_schema" toml:"output_schema"`
+
+	// outputSchema is the resolved OutputSchema, set by Validate.
+	outputSchema *jsonschema.Resolved
+
+	// MaxTurns lets an agent request guidance or other agents before answering,
+	// for up to this many model turns in total. 0 or 1 means a single turn.
+	MaxTurns int `yaml:"max_turns" toml:"max_turns"`
+
+	// Executor pins the backend that runs an agent. By default, agents use
+	// sampling and fall back to the local executor if the client cannot sample.
+	Executor Executor `yaml:"executor" toml:"executor"`
+
+	// Timeout limits each execution attempt of an agent (e.g., "2m").
+	// Overrides the configured execution timeout.
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+
+	// template is the parsed Body of entries with arguments, set when the store is loaded.
+	template *template.Template
+
+	Body string `yaml:"-" toml:"-"`
+}
+
+// HasTags reports whether the entry has all of the given tags.
+func (e *Entry) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(e.Tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// DeprecationNotice returns a notice to prepend to a deprecated entry's
+// content, naming its replacement if it has one. Returns "" if not deprecated.
+func (e *Entry) DeprecationNotice() string {
+	if !e.Deprecated {
+		return ""
+	}
+
+	notice := cmp.Or(e.DeprecationMessage, fmt.Sprintf("This %s is deprecated.", e.Type))
+	if e.ReplacedBy != "" {
+		notice += fmt.Sprintf(" Use `%s` instead.", e.ReplacedBy)
+	}
+
+	return "> **Deprecated:** " + strings.TrimSpace(notice) + "\n\n"
+}
+
+// FormatSeverity renders a rule's severity as " [must]", or "" for other entries.
+func (e *Entry) FormatSeverity() string {
+	if e.Severity == "" {
+		return ""
+	}
+
+	return " [" + string(e.Severity) + "]"
 }
 
 func (e *Entry) FormatGlobs() string {
@@ -63,6 +214,47 @@ func (e *Entry) FormatGlobs() string {
 	return " (" + strings.Join(e.Globs, ", ") + ")"
 }
 
+// FormatAgents renders delegated agents as " [agents: a, b]", or "" if there are none.
+func (e *Entry) FormatAgents() string {
+	if len(e.Agents) == 0 {
+		return ""
+	}
+
+	return " [agents: " + strings.Join(e.Agents, ", ") + "]"
+}
+
+// FormatArguments renders argument names as " [arguments: a*, b]", marking
+// the ones that need a value with "*", or "" if there are none.
+func (e *Entry) FormatArguments() string {
+	if len(e.Arguments) == 0 {
+		return ""
+	}
+
+	names := make([]string, len(e.Arguments))
+	for i, arg := range e.Arguments {
+		names[i] = arg.Name
+		if arg.NeedsValue() {
+			names[i] += "*"
+		}
+	}
+
+	return " [arguments: " + strings.Join(names, ", ") + "]"
+}
+
+// FormatSteps renders pipeline steps as " (a, b -> c)", or "" for plain agents.
+func (e *Entry) FormatSteps() string {
+	if !e.IsPipeline() {
+		return ""
+	}
+
+	steps := make([]string, len(e.Steps))
+	for i, step := range e.Steps {
+		steps[i] = strings.Join(step.Agents, ", ")
+	}
+
+	return " (" + strings.Join(steps, " -> ") + ")"
+}
+
 func (e *Entry) Validate() error {
 	for _, pattern := range e.Globs {
 		_, err := filepath.Match(pattern, "")
@@ -71,19 +263,197 @@ func (e *Entry) Validate() error {
 		}
 	}
 
+	if e.Timeout < 0 {
+		return fmt.Errorf("timeout %s: %w", e.Timeout, ErrInvalidTimeout)
+	}
+
+	if len(e.Agents) > 0 && e.Type != TypeSkill {
+		return fmt.Errorf("%w: only skills can delegate to agents", ErrInvalidDelegation)
+	}
+
+	switch {
+	case e.Severity == "":
+	case e.Type != TypeRule:
+		return fmt.Errorf("%w: only rules have a severity", ErrInvalidSeverity)
+	case !e.Severity.Valid():
+		return fmt.Errorf("%w: %q must be must, should or may", ErrInvalidSeverity, e.Severity)
+	}
+
+	err := e.validateTags()
+	if err != nil {
+		return err
+	}
+
+	err = e.validateDeprecation()
+	if err != nil {
+		return err
+	}
+
+	err = e.validateAgentFields()
+	if err != nil {
+		return err
+	}
+
+	for i := range e.Arguments {
+		arg := &e.Arguments[i]
+
+		if slices.ContainsFunc(e.Arguments[:i], func(a Argument) bool { return a.Name == arg.Name }) {
+			return fmt.Errorf("%w: duplicate argument %q", ErrInvalidArgument, arg.Name)
+		}
+
+		err := arg.validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateTags checks that tags are unique and lowercase.
+func (e *Entry) validateTags() error {
+	for i, tag := range e.Tags {
+		if !tagPattern.MatchString(tag) {
+			return fmt.Errorf("%w: %q must be lowercase letters, digits and dashes", ErrInvalidTag, tag)
+		}
+
+		if slices.Contains(e.Tags[:i], tag) {
+			return fmt.Errorf("%w: duplicate tag %q", ErrInvalidTag, tag)
+		}
+	}
+
+	return nil
+}
+
+// validateDeprecation checks that deprecation details are only set on
+// deprecated entries and that aliases are unique.
+func (e *Entry) validateDeprecation() error {
+	if !e.Deprecated && (e.DeprecationMessage != "" || e.ReplacedBy != "") {
+		return fmt.Errorf("%w: deprecation_message and replaced_by require deprecated: true", ErrInvalidDeprecation)
+	}
+
+	for i, alias := range e.Aliases {
+		if alias == "" || slices.Contains(e.Aliases[:i], alias) {
+			return fmt.Errorf("%w: alias %q must be unique and not empty", ErrInvalidDeprecation, alias)
+		}
+	}
+
+	return nil
+}
+
+// validateAgentFields checks the fields that only agents accept.
+func (e *Entry) validateAgentFields() error {
+	if len(e.Steps) > 0 && e.Type != TypeAgent {
+		return fmt.Errorf("%w: only agents can define steps", ErrInvalidPipeline)
+	}
+
+	for i, step := range e.Steps {
+		if len(step.Agents) == 0 {
+			return fmt.Errorf("%w: step %d has no agents", ErrInvalidPipeline, i+1)
+		}
+	}
+
+	if e.OutputSchema != nil {
+		if e.Type != TypeAgent {
+			return fmt.Errorf("%w: only agents accept an output schema", ErrInvalidOutputSchema)
+		}
+
+		resolved, err := resolveSchema(e.OutputSchema)
+		if err != nil {
+			return err
+		}
+
+		e.outputSchema = resolved
+	}
+
+	if e.Sampling != nil {
+		if e.Type != TypeAgent {
+			return fmt.Errorf("%w: only agents accept sampling parameters", ErrInvalidSampling)
+		}
+
+		err := e.Sampling.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	if e.MaxTurns != 0 {
+		if e.Type != TypeAgent || e.IsPipeline() {
+			return fmt.Errorf("%w: only agents without steps run multiple turns", ErrInvalidMaxTurns)
+		}
+
+		if e.MaxTurns < 0 || e.MaxTurns > MaxTurnsLimit {
+			return fmt.Errorf("%w: %d must be between 1 and %d", ErrInvalidMaxTurns, e.MaxTurns, MaxTurnsLimit)
+		}
+	}
+
+	if e.Executor != "" {
+		if e.Type != TypeAgent || e.IsPipeline() {
+			return fmt.Errorf("%w: only agents without steps choose an executor", ErrInvalidExecutor)
+		}
+
+		if !e.Executor.Valid() {
+			return fmt.Errorf("%w: %q", ErrInvalidExecutor, e.Executor)
+		}
+	}
+
 	return nil
 }
 
-// RenderBody substitutes argument values into the body using {{argName}} syntax.
-// Arguments not provided in values are replaced with empty strings.
-func (e *Entry) RenderBody(values map[string]string) string {
-	result := e.Body
+// ResolvedOutputSchema returns the validated output schema, or nil if the
+// entry has none or has not been validated.
+func (e *Entry) ResolvedOutputSchema() *jsonschema.Resolved {
+	return e.outputSchema
+}
+
+// resolveSchema converts a schema decoded from frontmatter into a resolved JSON schema.
+func resolveSchema(raw map[string]any) (*jsonschema.Resolved, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOutputSchema, err)
+	}
+
+	var schema jsonschema.Schema
+
+	err = json.Unmarshal(data, &schema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOutputSchema, err)
+	}
+
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOutputSchema, err)
+	}
+
+	return resolved, nil
+}
+
+// IsPipeline reports whether the entry is an agent pipeline.
+func (e *Entry) IsPipeline() bool {
+	return len(e.Steps) > 0
+}
+
+// Argument returns the argument with the given name, or nil if not declared.
+func (e *Entry) Argument(name string) *Argument {
+	for i := range e.Arguments {
+		if e.Arguments[i].Name == name {
+			return &e.Arguments[i]
+		}
+	}
+
+	return nil
+}
+
+// MissingArguments returns the names of required arguments without a non-empty
+// value or default.
+func (e *Entry) MissingArguments(values map[string]string) []string {
+	var missing []string
 
 	for _, arg := range e.Arguments {
-		placeholder := "{{" + arg.Name + "}}"
-		value := values[arg.Name]
-		result = strings.ReplaceAll(result, placeholder, value)
+		if arg.NeedsValue() && values[arg.Name] == "" {
+			missing = append(missing, arg.Name)
+		}
 	}
 
-	return result
+	return missing
 }
@@ -23,8 +23,75 @@ var ErrSourceNotFound = errors.New("source path not found")
 // ErrNotDirectory is returned when a source path is not a directory.
 var ErrNotDirectory = errors.New("not a directory")
 
-// ErrFilterConflict is returned when both allow and block are configured.
-var ErrFilterConflict = errors.New("allow and block cannot both be set")
+// ErrInvalidFilter is returned when a filter pattern is not a valid glob or regular expression.
+var ErrInvalidFilter = errors.New("invalid filter pattern")
 
 // ErrInvalidGlob is returned when a glob pattern is malformed.
 var ErrInvalidGlob = errors.New("invalid glob pattern")
+
+// ErrMissingArguments is returned when required arguments are not provided.
+var ErrMissingArguments = errors.New("missing required arguments")
+
+// ErrInvalidConcurrency is returned when the agent concurrency limit is negative.
+var ErrInvalidConcurrency = errors.New("concurrency must not be negative")
+
+// ErrInvalidSampling is returned when agent sampling parameters are out of range.
+var ErrInvalidSampling = errors.New("invalid sampling parameters")
+
+// ErrInvalidTimeout is returned when a timeout is negative.
+var ErrInvalidTimeout = errors.New("timeout must not be negative")
+
+// ErrInvalidRetries is returned when the retry count is negative.
+var ErrInvalidRetries = errors.New("retries must not be negative")
+
+// ErrInvalidPipeline is returned when pipeline steps are malformed or reference unknown agents.
+var ErrInvalidPipeline = errors.New("invalid pipeline")
+
+// ErrUnknownAgent is returned when a skill delegates to an agent that no source defines.
+var ErrUnknownAgent = errors.New("unknown agent")
+
+// ErrInvalidDelegation is returned when an entry other than a skill delegates to agents.
+var ErrInvalidDelegation = errors.New("invalid delegation")
+
+// ErrInvalidOutputSchema is returned when an agent's output schema is malformed.
+var ErrInvalidOutputSchema = errors.New("invalid output schema")
+
+// ErrInvalidExecutor is returned when an agent executor or its configuration is invalid.
+var ErrInvalidExecutor = errors.New("invalid executor")
+
+// ErrInvalidMaxTurns is returned when an agent's max_turns is out of range.
+var ErrInvalidMaxTurns = errors.New("invalid max turns")
+
+// ErrInvalidArgument is returned when an argument declaration is invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// ErrInvalidTemplate is returned when an entry body is not a valid template.
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// ErrRender is returned when an entry body fails to render.
+var ErrRender = errors.New("render failed")
+
+// ErrInvalidArgumentValue is returned when an argument value does not match its declaration.
+var ErrInvalidArgumentValue = errors.New("invalid argument value")
+
+// ErrInvalidInclude is returned when an include directive is malformed or references an entry that is not loaded.
+var ErrInvalidInclude = errors.New("invalid include")
+
+// ErrIncludeCycle is returned when entries include each other.
+var ErrIncludeCycle = errors.New("include cycle")
+
+// ErrInvalidExtends is returned when an entry extends an entry that is not loaded, or entries extend each other.
+var ErrInvalidExtends = errors.New("invalid extends")
+
+// ErrInvalidTag is returned when a tag is malformed or repeated.
+var ErrInvalidTag = errors.New("invalid tag")
+
+// ErrInvalidSeverity is returned when a severity is not must, should or may, or is set on an entry other than a rule.
+var ErrInvalidSeverity = errors.New("invalid severity")
+
+// ErrInvalidDeprecation is returned when deprecation details or aliases are invalid,
+// or a replacement is not loaded.
+var ErrInvalidDeprecation = errors.New("invalid deprecation")
+
+// ErrInvalidBundle is returned when a bundle file or one of its entries is malformed.
+var ErrInvalidBundle = errors.New("invalid bundle")
@@ -0,0 +1,264 @@
+package grimoire
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// appendMarker is the heading suffix that appends a section to the base
+// section of the same heading instead of replacing it.
+const appendMarker = "(append)"
+
+// headingPattern matches ATX markdown headings, capturing the level and title.
+var headingPattern = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+
+// section is a markdown heading with the text up to the next heading.
+type section struct {
+	level int
+	title string
+
+	// text is the heading line and content, without surrounding blank lines.
+	text string
+}
+
+// extendsResolver merges extending entries with their bases.
+type extendsResolver struct {
+	store *Store
+	state map[*Entry]resolveState
+}
+
+// resolveExtends merges every extending entry with its base, resolving bases
+// that extend other entries first, and validates the merged entries.
+func (s *Store) resolveExtends() error {
+	r := &extendsResolver{store: s, state: make(map[*Entry]resolveState)}
+
+	for _, typ := range []Type{TypeRule, TypeSkill, TypeInstruction, TypeAgent} {
+		for _, entry := range s.List(typ) {
+			err := r.resolve(entry)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *extendsResolver) resolve(entry *Entry) error {
+	if entry.Extends == "" || r.state[entry] == resolved {
+		return nil
+	}
+
+	if r.state[entry] == resolving {
+		return fmt.Errorf("%s %q: %w: %q extends itself through its bases", entry.Type, entry.Name, ErrInvalidExtends, entry.Name)
+	}
+
+	r.state[entry] = resolving
+
+	base, err := r.store.base(entry)
+	if err != nil {
+		return fmt.Errorf("%s %q: %w", entry.Type, entry.Name, err)
+	}
+
+	err = r.resolve(base)
+	if err != nil {
+		return err
+	}
+
+	entry.inherit(base)
+
+	err = entry.Validate()
+	if err != nil {
+		return fmt.Errorf("%s %q extending %q: %w", entry.Type, entry.Name, base.Name, err)
+	}
+
+	r.state[entry] = resolved
+
+	return nil
+}
+
+// base returns the entry that entry extends. An entry extending its own name
+// extends the entry it shadowed when loaded. Entries that were filtered out can
+// still be extended, as they were read from the sources.
+func (s *Store) base(entry *Entry) (*Entry, error) {
+	if entry.Extends == entry.Name {
+		base, ok := s.shadowed[entry.Type][entry.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: no other %s %q is loaded", ErrInvalidExtends, entry.Type, entry.Name)
+		}
+
+		return base, nil
+	}
+
+	base, err := s.Get(entry.Type, entry.Extends)
+	if err == nil {
+		return base, nil
+	}
+
+	base = s.filtered(entry.Type, entry.Extends)
+	if base == nil {
+		return nil, fmt.Errorf("%w: no source defines %s %q", ErrInvalidExtends, entry.Type, entry.Extends)
+	}
+
+	return base, nil
+}
+
+// inheritsTags reports whether the entry takes its tags from its base.
+func (e *Entry) inheritsTags() bool {
+	return e.Extends != "" && e.Tags == nil
+}
+
+// inherit fills the fields entry leaves unset from base and merges the bodies.
+func (e *Entry) inherit(base *Entry) {
+	e.Description = cmp.Or(e.Description, base.Description)
+	e.Order = cmp.Or(e.Order, base.Order)
+	e.Severity = cmp.Or(e.Severity, base.Severity)
+	e.MaxTurns = cmp.Or(e.MaxTurns, base.MaxTurns)
+	e.Executor = cmp.Or(e.Executor, base.Executor)
+	e.Timeout = cmp.Or(e.Timeout, base.Timeout)
+
+	if e.Globs == nil {
+		e.Globs = slices.Clone(base.Globs)
+	}
+
+	if e.Tags == nil {
+		e.Tags = slices.Clone(base.Tags)
+	}
+
+	if e.Arguments == nil {
+		e.Arguments = slices.Clone(base.Arguments)
+	}
+
+	if e.Agents == nil {
+		e.Agents = slices.Clone(base.Agents)
+	}
+
+	if e.Steps == nil {
+		e.Steps = slices.Clone(base.Steps)
+	}
+
+	if e.Sampling == nil {
+		e.Sampling = base.Sampling
+	}
+
+	if e.OutputSchema == nil {
+		e.OutputSchema = base.OutputSchema
+	}
+
+	e.Body = mergeBody(base.Body, e.Body)
+}
+
+// mergeBody merges an extending body into its base by markdown heading.
+// A section replaces the base section with the same heading and level, including
+// its subsections; with the "(append)" suffix, its content is added to the end of
+// that section instead. Sections not in the base are added at the end, and text
+// before the first heading is added to the base's text before its first heading.
+func mergeBody(base, body string) string {
+	basePreamble, sections := splitSections(base)
+	preamble, extending := splitSections(body)
+
+	for i := 0; i < len(extending); {
+		end := sectionEnd(extending, i)
+		block := slices.Clone(extending[i:end])
+		i = end
+
+		title, appending := strings.CutSuffix(block[0].title, appendMarker)
+		title = strings.TrimSpace(title)
+
+		j := slices.IndexFunc(sections, func(s section) bool {
+			return s.level == block[0].level && strings.EqualFold(s.title, title)
+		})
+		if j < 0 {
+			if appending {
+				block[0].text = strings.Replace(block[0].text, block[0].title, title, 1)
+			}
+
+			sections = append(sections, block...)
+
+			continue
+		}
+
+		k := sectionEnd(sections, j)
+
+		if appending {
+			_, content, _ := strings.Cut(block[0].text, "\n")
+			sections[k-1].text = joinBlocks(sections[k-1].text, strings.TrimSpace(content))
+			sections = slices.Insert(sections, k, block[1:]...)
+
+			continue
+		}
+
+		sections = slices.Replace(sections, j, k, block...)
+	}
+
+	parts := []string{joinBlocks(basePreamble, preamble)}
+	for _, s := range sections {
+		parts = append(parts, s.text)
+	}
+
+	return joinBlocks(parts...) + "\n"
+}
+
+// splitSections splits a markdown body into the text before the first heading
+// and the sections after it. Headings in fenced code blocks are ignored.
+func splitSections(body string) (string, []section) {
+	var (
+		preamble strings.Builder
+		sections []section
+		fence    string
+	)
+
+	for line := range strings.Lines(body) {
+		trimmed := strings.TrimSpace(line)
+
+		if fence == "" && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")) {
+			fence = trimmed[:3]
+		} else if fence != "" && strings.HasPrefix(trimmed, fence) {
+			fence = ""
+		}
+
+		m := headingPattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+
+		switch {
+		case fence == "" && m != nil:
+			sections = append(sections, section{level: len(m[1]), title: m[2], text: line})
+		case len(sections) > 0:
+			sections[len(sections)-1].text += line
+		default:
+			preamble.WriteString(line)
+		}
+	}
+
+	for i := range sections {
+		sections[i].text = strings.TrimSpace(sections[i].text)
+	}
+
+	return strings.TrimSpace(preamble.String()), sections
+}
+
+// sectionEnd returns the index after the section at i and its subsections.
+func sectionEnd(sections []section, i int) int {
+	for j := i + 1; j < len(sections); j++ {
+		if sections[j].level <= sections[i].level {
+			return j
+		}
+	}
+
+	return len(sections)
+}
+
+// joinBlocks joins non-empty blocks of markdown with blank lines.
+func joinBlocks(blocks ...string) string {
+	var nonEmpty []string
+
+	for _, b := range blocks {
+		if b != "" {
+			nonEmpty = append(nonEmpty, b)
+		}
+	}
+
+	return strings.Join(nonEmpty, "\n\n")
+}
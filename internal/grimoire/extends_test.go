@@ -0,0 +1,92 @@
+package grimoire
+
+import "testing"
+
+func TestMergeBody(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		base string
+		body string
+		want string
+	}{
+		{
+			name: "replaces section with same heading",
+			base: "# Review\n\n## Format\n\nOld format.\n\n## Scope\n\nEverything.\n",
+			body: "## Format\n\nNew format.\n",
+			want: "# Review\n\n## Format\n\nNew format.\n\n## Scope\n\nEverything.\n",
+		},
+		{
+			name: "replaces subsections with their section",
+			base: "## Format\n\nOld.\n\n### Example\n\nOld example.\n\n## Scope\n\nAll.\n",
+			body: "## Format\n\nNew.\n",
+			want: "## Format\n\nNew.\n\n## Scope\n\nAll.\n",
+		},
+		{
+			name: "appends to section",
+			base: "## Checks\n\n1. Correctness\n\n## Scope\n\nAll.\n",
+			body: "## Checks (append)\n\n2. Logging\n",
+			want: "## Checks\n\n1. Correctness\n\n2. Logging\n\n## Scope\n\nAll.\n",
+		},
+		{
+			name: "appends after subsections",
+			base: "## Checks\n\nIntro.\n\n### Style\n\nGofmt.\n\n## Scope\n\nAll.\n",
+			body: "## Checks (append)\n\nMore.\n",
+			want: "## Checks\n\nIntro.\n\n### Style\n\nGofmt.\n\nMore.\n\n## Scope\n\nAll.\n",
+		},
+		{
+			name: "adds new sections at the end",
+			base: "## Format\n\nText.\n",
+			body: "## Follow-up\n\nFile tickets.\n",
+			want: "## Format\n\nText.\n\n## Follow-up\n\nFile tickets.\n",
+		},
+		{
+			name: "adds appended section missing from base without marker",
+			base: "## Format\n\nText.\n",
+			body: "## Notes (append)\n\nExtra.\n",
+			want: "## Format\n\nText.\n\n## Notes\n\nExtra.\n",
+		},
+		{
+			name: "matches headings case-insensitively",
+			base: "## Review Format\n\nOld.\n",
+			body: "## review format\n\nNew.\n",
+			want: "## review format\n\nNew.\n",
+		},
+		{
+			name: "does not match headings of another level",
+			base: "## Format\n\nOld.\n",
+			body: "### Format\n\nNew.\n",
+			want: "## Format\n\nOld.\n\n### Format\n\nNew.\n",
+		},
+		{
+			name: "merges preambles",
+			base: "Base intro.\n\n## Format\n\nText.\n",
+			body: "Team conventions apply.\n",
+			want: "Base intro.\n\nTeam conventions apply.\n\n## Format\n\nText.\n",
+		},
+		{
+			name: "ignores headings in code fences",
+			base: "## Format\n\n```markdown\n## Not a heading\n```\n",
+			body: "## Not a heading\n\nReal.\n",
+			want: "## Format\n\n```markdown\n## Not a heading\n```\n\n## Not a heading\n\nReal.\n",
+		},
+		{
+			name: "keeps base for empty body",
+			base: "# Title\n\nText.\n",
+			body: "",
+			want: "# Title\n\nText.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := mergeBody(tt.base, tt.body)
+			if got != tt.want {
+				t.Errorf("mergeBody() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
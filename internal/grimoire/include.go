@@ -0,0 +1,131 @@
+package grimoire
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// includePattern matches include directives such as "{{> rules/error-handling}}".
+var includePattern = regexp.MustCompile(`\{\{>\s*([^\s{}]+)\s*\}\}`)
+
+// resolveState tracks the resolution of an entry's includes or extends.
+type resolveState int
+
+const (
+	resolvePending resolveState = iota
+	resolving
+	resolved
+)
+
+// includeResolver expands include directives across all loaded entries.
+type includeResolver struct {
+	store *Store
+	state map[*Entry]resolveState
+
+	// stack holds the references being resolved, for cycle errors.
+	stack []string
+}
+
+// resolveIncludes replaces include directives in entry bodies with the bodies
+// of the referenced entries, recursively, and then parses entry templates.
+// Includes are resolved before templates, so included text can use the
+// including entry's arguments.
+func (s *Store) resolveIncludes() error {
+	r := &includeResolver{store: s, state: make(map[*Entry]resolveState)}
+
+	for _, typ := range []Type{TypeRule, TypeSkill, TypeInstruction, TypeAgent} {
+		for _, entry := range s.List(typ) {
+			err := r.resolve(entry)
+			if err != nil {
+				return err
+			}
+
+			err = entry.parseTemplate()
+			if err != nil {
+				return fmt.Errorf("%s %q: %w", entry.Type, entry.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolve expands the includes of entry, resolving referenced entries first.
+// Errors name the entry containing the failing directive.
+func (r *includeResolver) resolve(entry *Entry) error {
+	ref := includeRef(entry)
+
+	switch r.state[entry] {
+	case resolved:
+		return nil
+	case resolving:
+		return fmt.Errorf("%w: %s -> %s", ErrIncludeCycle, strings.Join(r.stack, " -> "), ref)
+	case resolvePending:
+	}
+
+	r.state[entry] = resolving
+	r.stack = append(r.stack, ref)
+
+	var resolveErr error
+
+	body := includePattern.ReplaceAllStringFunc(entry.Body, func(directive string) string {
+		if resolveErr != nil {
+			return directive
+		}
+
+		target, err := r.store.lookupInclude(includePattern.FindStringSubmatch(directive)[1])
+		if err != nil {
+			resolveErr = fmt.Errorf("%s %q: %w", entry.Type, entry.Name, err)
+
+			return directive
+		}
+
+		err = r.resolve(target)
+		if err != nil {
+			resolveErr = err
+
+			return directive
+		}
+
+		return strings.TrimRight(target.Body, "\n")
+	})
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	entry.Body = body
+	r.stack = r.stack[:len(r.stack)-1]
+	r.state[entry] = resolved
+
+	return nil
+}
+
+// lookupInclude returns the entry named by an include reference of the form
+// "<type>s/<name>" or "<type>/<name>", e.g. "rules/go/errors". Entries that
+// were filtered out can still be included, as they were read from the sources.
+func (s *Store) lookupInclude(ref string) (*Entry, error) {
+	prefix, name, _ := strings.Cut(ref, "/")
+	typ := Type(strings.TrimSuffix(prefix, "s"))
+
+	if !typ.Valid() || name == "" {
+		return nil, fmt.Errorf("%w %q: must be <type>/<name>, e.g. rules/error-handling", ErrInvalidInclude, ref)
+	}
+
+	entry, err := s.Get(typ, name)
+	if err == nil {
+		return entry, nil
+	}
+
+	entry = s.filtered(typ, name)
+	if entry == nil {
+		return nil, fmt.Errorf("%w %q: no source defines %s %q", ErrInvalidInclude, ref, typ, name)
+	}
+
+	return entry, nil
+}
+
+// includeRef returns the include reference for entry, e.g. "rules/go/errors".
+func includeRef(entry *Entry) string {
+	return string(entry.Type) + "s/" + entry.Name
+}
@@ -2,38 +2,112 @@ package grimoire
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v3"
 )
 
 const frontmatterDelimiter = "---"
 
-// parseMarkdown parses a markdown file with YAML frontmatter.
-// The type is determined from the frontmatter "type" field.
+// frontmatterFormat is a frontmatter syntax, detected by its delimiter line.
+type frontmatterFormat struct {
+	delimiter string
+	decode    func(data []byte, v any) error
+
+	// bundleName matches the start of frontmatter whose first field is the
+	// entry's name, which opens each entry in a markdown bundle.
+	bundleName *regexp.Regexp
+}
+
+// frontmatterFormats are the supported frontmatter syntaxes: YAML, TOML and JSON.
+var frontmatterFormats = []frontmatterFormat{
+	{delimiter: frontmatterDelimiter, decode: yaml.Unmarshal, bundleName: regexp.MustCompile(`^name:`)},
+	{delimiter: "+++", decode: toml.Unmarshal, bundleName: regexp.MustCompile(`^name\s*=`)},
+	{delimiter: ";;;", decode: decodeJSON, bundleName: regexp.MustCompile(`^\{\s*"name"\s*:`)},
+}
+
+// parseMarkdown parses a markdown file with YAML, TOML or JSON frontmatter,
+// detected by its delimiter. The type is determined from the frontmatter "type" field.
+// Line numbers in frontmatter errors are lines of the file.
 func parseMarkdown(data []byte) (*Entry, error) {
 	entry := &Entry{}
 
-	if !bytes.HasPrefix(data, []byte(frontmatterDelimiter)) {
+	i := slices.IndexFunc(frontmatterFormats, func(f frontmatterFormat) bool {
+		return bytes.HasPrefix(data, []byte(f.delimiter))
+	})
+	if i < 0 {
 		entry.Body = string(data)
 
 		return entry, nil
 	}
 
-	rest := data[len(frontmatterDelimiter):]
-	frontmatter, body, found := bytes.Cut(rest, []byte("\n"+frontmatterDelimiter))
+	format := frontmatterFormats[i]
 
-	if !found {
-		return nil, fmt.Errorf("unclosed: %w", ErrInvalidFrontmatter)
+	frontmatter, body, err := splitFrontmatter(data, format.delimiter)
+	if err != nil {
+		return nil, err
 	}
 
-	err := yaml.Unmarshal(frontmatter, entry)
+	err = format.decode(frontmatter, entry)
 	if err != nil {
 		return nil, fmt.Errorf("parsing frontmatter: %w", err)
 	}
 
-	entry.Body = strings.TrimPrefix(string(body), "\n")
+	entry.Body = string(body)
 
 	return entry, nil
 }
+
+// splitFrontmatter splits markdown starting with a frontmatter delimiter into
+// the frontmatter and the body after the closing delimiter. The frontmatter
+// starts with the end of the opening delimiter line, so its line numbers are
+// those of the file.
+func splitFrontmatter(data []byte, delimiter string) ([]byte, []byte, error) {
+	rest := data[len(delimiter):]
+	frontmatter, body, found := bytes.Cut(rest, []byte("\n"+delimiter))
+
+	if !found {
+		return nil, nil, fmt.Errorf("unclosed: %w", ErrInvalidFrontmatter)
+	}
+
+	return frontmatter, []byte(strings.TrimPrefix(string(body), "\n")), nil
+}
+
+// decodeJSON decodes JSON frontmatter. JSON is valid YAML, so once the syntax
+// is checked, it is decoded like YAML frontmatter. Errors read "json: line N: ...".
+func decodeJSON(data []byte, v any) error {
+	var syntaxErr *json.SyntaxError
+
+	err := json.Unmarshal(data, new(map[string]any))
+	if errors.As(err, &syntaxErr) {
+		return fmt.Errorf("json: line %d: %w", lineAt(data, syntaxErr.Offset), err)
+	}
+
+	if err != nil {
+		return fmt.Errorf("json: %w: must be an object", ErrInvalidFrontmatter)
+	}
+
+	var typeErr *yaml.TypeError
+
+	err = yaml.Unmarshal(data, v)
+	if errors.As(err, &typeErr) {
+		return fmt.Errorf("json: %s: %w", strings.Join(typeErr.Errors, "; "), ErrInvalidFrontmatter)
+	}
+
+	if err != nil {
+		return fmt.Errorf("json: %w", err)
+	}
+
+	return nil
+}
+
+// lineAt returns the line of data that the byte offset falls on.
+func lineAt(data []byte, offset int64) int {
+	return bytes.Count(data[:min(int(offset), len(data))], []byte("\n")) + 1
+}
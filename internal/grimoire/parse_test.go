@@ -0,0 +1,161 @@
+package grimoire
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+		want Entry
+	}{
+		{
+			name: "YAML",
+			data: "---\ntype: rule\ndescription: Wrap errors\nglobs: [\"*.go\"]\n---\nBody.\n",
+			want: Entry{Type: TypeRule, Description: "Wrap errors", Globs: []string{"*.go"}, Body: "Body.\n"},
+		},
+		{
+			name: "TOML",
+			data: "+++\ntype = \"rule\"\ndescription = \"Wrap errors\"\nglobs = [\"*.go\"]\n+++\nBody.\n",
+			want: Entry{Type: TypeRule, Description: "Wrap errors", Globs: []string{"*.go"}, Body: "Body.\n"},
+		},
+		{
+			name: "JSON",
+			data: ";;;\n{\n  \"type\": \"rule\",\n  \"description\": \"Wrap errors\",\n  \"globs\": [\"*.go\"]\n}\n;;;\nBody.\n",
+			want: Entry{Type: TypeRule, Description: "Wrap errors", Globs: []string{"*.go"}, Body: "Body.\n"},
+		},
+		{
+			name: "no frontmatter",
+			data: "# Title\n\n---\n",
+			want: Entry{Body: "# Title\n\n---\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseMarkdown([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("parseMarkdown() error = %v", err)
+			}
+
+			if got.Type != tt.want.Type || got.Description != tt.want.Description ||
+				strings.Join(got.Globs, ",") != strings.Join(tt.want.Globs, ",") || got.Body != tt.want.Body {
+				t.Errorf("parseMarkdown() = {%q %q %q %q}, want {%q %q %q %q}",
+					got.Type, got.Description, got.Globs, got.Body,
+					tt.want.Type, tt.want.Description, tt.want.Globs, tt.want.Body)
+			}
+		})
+	}
+}
+
+func TestParseMarkdownErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+		want error
+
+		// contains is part of the error message, with the line of the file.
+		contains string
+	}{
+		{
+			name:     "YAML type mismatch",
+			data:     "---\ntype: rule\nglobs: x\n---\n",
+			contains: "line 3",
+		},
+		{
+			name:     "TOML type mismatch",
+			data:     "+++\ntype = \"rule\"\nglobs = \"x\"\n+++\n",
+			contains: "toml: line 3",
+		},
+		{
+			name:     "JSON syntax error",
+			data:     ";;;\n{\n  \"type\": \"rule\",\n  \"globs\": [\n}\n;;;\n",
+			contains: "json: line 5",
+		},
+		{
+			name:     "JSON type mismatch",
+			data:     ";;;\n{\"type\": \"rule\", \"globs\": \"x\"}\n;;;\n",
+			want:     ErrInvalidFrontmatter,
+			contains: "json: ",
+		},
+		{
+			name:     "JSON that is not an object",
+			data:     ";;;\n[1]\n;;;\n",
+			want:     ErrInvalidFrontmatter,
+			contains: "must be an object",
+		},
+		{
+			name:     "unclosed YAML",
+			data:     "---\ntype: rule\n",
+			want:     ErrInvalidFrontmatter,
+			contains: "unclosed",
+		},
+		{
+			name:     "unclosed TOML",
+			data:     "+++\ntype = \"rule\"\n---\n",
+			want:     ErrInvalidFrontmatter,
+			contains: "unclosed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseMarkdown([]byte(tt.data))
+			if err == nil {
+				t.Fatal("parseMarkdown() error = nil")
+			}
+
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("parseMarkdown() error = %v, want %v", err, tt.want)
+			}
+
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("parseMarkdown() error = %q, want it to contain %q", err, tt.contains)
+			}
+		})
+	}
+}
+
+func TestSplitFrontmatter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		data        string
+		delimiter   string
+		frontmatter string
+		body        string
+	}{
+		{"YAML", "---\na: 1\n---\nBody.\n", "---", "\na: 1", "Body.\n"},
+		{"TOML", "+++\na = 1\n+++\nBody.\n", "+++", "\na = 1", "Body.\n"},
+		{"JSON", ";;;\n{\"a\": 1}\n;;;\nBody.\n", ";;;", "\n{\"a\": 1}", "Body.\n"},
+		{"empty body", "---\na: 1\n---\n", "---", "\na: 1", ""},
+		{"other delimiters in frontmatter", "+++\na = \"---\"\n+++\n", "+++", "\na = \"---\"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			frontmatter, body, err := splitFrontmatter([]byte(tt.data), tt.delimiter)
+			if err != nil {
+				t.Fatalf("splitFrontmatter() error = %v", err)
+			}
+
+			if string(frontmatter) != tt.frontmatter || string(body) != tt.body {
+				t.Errorf("splitFrontmatter() = %q, %q, want %q, %q", frontmatter, body, tt.frontmatter, tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package grimoire
+
+import "fmt"
+
+// DefaultMaxTokens is the sampling token limit used when an agent does not set one.
+const DefaultMaxTokens = 4096
+
+const (
+	maxTemperature = 2.0
+	maxPriority    = 1.0
+)
+
+// Sampling holds the sampling parameters of an agent.
+// Zero values mean "not set" and leave the choice to the client.
+type Sampling struct {
+	// MaxTokens limits the length of the response. Default: DefaultMaxTokens.
+	MaxTokens int64 `yaml:"max_tokens" toml:"max_tokens"`
+
+	// Temperature controls randomness (0-2). Nil leaves it to the client,
+	// so that 0 can be requested for deterministic output.
+	Temperature *float64 `yaml:"temperature" toml:"temperature"`
+
+	// StopSequences end sampling when produced.
+	StopSequences []string `yaml:"stop_sequences" toml:"stop_sequences"`
+
+	// Models lists model name hints in order of preference (e.g., "sonnet").
+	Models []string `yaml:"models" toml:"models"`
+
+	// CostPriority, SpeedPriority and IntelligencePriority weight model selection (0-1).
+	CostPriority         float64 `yaml:"cost_priority"         toml:"cost_priority"`
+	SpeedPriority        float64 `yaml:"speed_priority"        toml:"speed_priority"`
+	IntelligencePriority float64 `yaml:"intelligence_priority" toml:"intelligence_priority"`
+}
+
+// TokenLimit returns the configured max tokens, or DefaultMaxTokens if unset.
+func (p *Sampling) TokenLimit() int64 {
+	if p == nil || p.MaxTokens == 0 {
+		return DefaultMaxTokens
+	}
+
+	return p.MaxTokens
+}
+
+func (p *Sampling) Validate() error {
+	if p.MaxTokens < 0 {
+		return fmt.Errorf("%w: max_tokens %d must not be negative", ErrInvalidSampling, p.MaxTokens)
+	}
+
+	if t := p.Temperature; t != nil && (*t < 0 || *t > maxTemperature) {
+		return fmt.Errorf("%w: temperature %g must be between 0 and %g", ErrInvalidSampling, *t, maxTemperature)
+	}
+
+	priorities := []struct {
+		name  string
+		value float64
+	}{
+		{"cost_priority", p.CostPriority},
+		{"speed_priority", p.SpeedPriority},
+		{"intelligence_priority", p.IntelligencePriority},
+	}
+
+	for _, priority := range priorities {
+		if priority.value < 0 || priority.value > maxPriority {
+			return fmt.Errorf("%w: %s %g must be between 0 and %g",
+				ErrInvalidSampling, priority.name, priority.value, maxPriority)
+		}
+	}
+
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"cmp"
 	"fmt"
 	"io/fs"
+	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"slices"
@@ -14,6 +16,30 @@ const minWordLength = 3
 
 type Store struct {
 	entries map[Type]map[string]*Entry
+
+	// shadowed holds entries replaced by an entry of the same name that extends them.
+	shadowed map[Type]map[string]*Entry
+
+	// aliases maps the aliases of each type to the names of their entries.
+	aliases map[Type]map[string]string
+
+	// considered holds every entry read from the sources, before filtering.
+	considered map[Type][]*Entry
+
+	// unmatched holds the filter patterns and tags that match no considered entry.
+	unmatched []UnmatchedFilter
+}
+
+// UnmatchedFilter is a filter pattern or tag that matches no entry in the sources,
+// e.g. because of a typo.
+type UnmatchedFilter struct {
+	// List names the filter list, e.g. "rules.block".
+	List    string
+	Pattern string
+}
+
+func (u UnmatchedFilter) String() string {
+	return fmt.Sprintf("%s: %q matches nothing", u.List, u.Pattern)
 }
 
 // New creates a store by loading content according to the provided config.
@@ -28,6 +54,9 @@ func New(cfg *Config, builtinFS fs.FS) (*Store, error) {
 			TypeInstruction: {},
 			TypeAgent:       {},
 		},
+		shadowed:   make(map[Type]map[string]*Entry),
+		aliases:    make(map[Type]map[string]string),
+		considered: make(map[Type][]*Entry),
 	}
 
 	// Load external paths first (higher priority for error messages)
@@ -58,9 +87,15 @@ func New(cfg *Config, builtinFS fs.FS) (*Store, error) {
 		}
 	}
 
+	err := s.resolve(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return s, nil
 }
 
+// Get returns the entry of the given type by name or alias.
 func (s *Store) Get(typ Type, name string) (*Entry, error) {
 	if name == "" {
 		return nil, fmt.Errorf("%s: %w", typ, ErrNameEmpty)
@@ -70,6 +105,10 @@ func (s *Store) Get(typ Type, name string) (*Entry, error) {
 		if entry, ok := entries[name]; ok {
 			return entry, nil
 		}
+
+		if entry, ok := entries[s.aliases[typ][name]]; ok {
+			return entry, nil
+		}
 	}
 
 	return nil, fmt.Errorf("%s %q: %w", typ, name, ErrNotFound)
@@ -91,6 +130,13 @@ func (s *Store) List(typ Type) []*Entry {
 	return result
 }
 
+// ListCurrent returns the entries of the given type that are not deprecated.
+func (s *Store) ListCurrent(typ Type) []*Entry {
+	return slices.DeleteFunc(s.List(typ), func(e *Entry) bool { return e.Deprecated })
+}
+
+// Search returns the entries that are not deprecated whose name, description,
+// tags or body match the query.
 func (s *Store) Search(query string) []*Entry {
 	query = strings.ToLower(query)
 
@@ -98,7 +144,7 @@ func (s *Store) Search(query string) []*Entry {
 
 	for _, entries := range s.entries {
 		for _, entry := range entries {
-			if matchesQuery(entry, query) {
+			if !entry.Deprecated && matchesQuery(entry, query) {
 				results = append(results, entry)
 			}
 		}
@@ -109,7 +155,7 @@ func (s *Store) Search(query string) []*Entry {
 	return results
 }
 
-// FindByTopics returns all rules whose description matches any of the given topics.
+// FindByTopics returns all current rules whose description matches any of the given topics.
 // Matching is case-insensitive substring match against description.
 func (s *Store) FindByTopics(topics []string) []*Entry {
 	if len(topics) == 0 {
@@ -126,7 +172,7 @@ func (s *Store) FindByTopics(topics []string) []*Entry {
 
 	// Only search rules (not skills) for topic matching
 	for _, entry := range s.entries[TypeRule] {
-		if matchesTopics(entry, normalizedTopics) {
+		if !entry.Deprecated && matchesTopics(entry, normalizedTopics) {
 			results = append(results, entry)
 		}
 	}
@@ -144,7 +190,7 @@ func (s *Store) FindByGlobs(files []string) []*Entry {
 	var results []*Entry
 
 	for _, entry := range s.entries[TypeRule] {
-		if matchesGlob(entry, files) {
+		if !entry.Deprecated && matchesGlob(entry, files) {
 			results = append(results, entry)
 		}
 	}
@@ -154,7 +200,7 @@ func (s *Store) FindByGlobs(files []string) []*Entry {
 	return results
 }
 
-// FindByTask returns all skills whose description matches the given task.
+// FindByTask returns all current skills whose description matches the given task.
 // Matching is case-insensitive and checks if task keywords appear in description.
 func (s *Store) FindByTask(task string) []*Entry {
 	if task == "" {
@@ -166,7 +212,7 @@ func (s *Store) FindByTask(task string) []*Entry {
 	var results []*Entry
 
 	for _, entry := range s.entries[TypeSkill] {
-		if matchesTask(entry, task) {
+		if !entry.Deprecated && matchesTask(entry, task) {
 			results = append(results, entry)
 		}
 	}
@@ -176,6 +222,141 @@ func (s *Store) FindByTask(task string) []*Entry {
 	return results
 }
 
+// UnmatchedFilters returns the configured filter patterns and tags that match
+// no entry in the sources.
+func (s *Store) UnmatchedFilters() []UnmatchedFilter {
+	return s.unmatched
+}
+
+// FindByTags returns all current entries that have all of the given tags.
+func (s *Store) FindByTags(tags []string) []*Entry {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	var results []*Entry
+
+	for _, entries := range s.entries {
+		for _, entry := range entries {
+			if !entry.Deprecated && entry.HasTags(tags) {
+				results = append(results, entry)
+			}
+		}
+	}
+
+	sortEntriesByName(results)
+
+	return results
+}
+
+// TagCount is a tag and the number of loaded entries that have it.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// Tags returns the tags of all current entries with their counts, sorted by tag.
+func (s *Store) Tags() []TagCount {
+	counts := make(map[string]int)
+
+	for _, entries := range s.entries {
+		for _, entry := range entries {
+			if entry.Deprecated {
+				continue
+			}
+
+			for _, tag := range entry.Tags {
+				counts[tag]++
+			}
+		}
+	}
+
+	result := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		result = append(result, TagCount{Tag: tag, Count: count})
+	}
+
+	slices.SortFunc(result, func(a, b TagCount) int {
+		return cmp.Compare(a.Tag, b.Tag)
+	})
+
+	return result
+}
+
+// SortBySeverity sorts entries from most to least strict severity, keeping
+// the order of entries with the same severity. Entries other than rules come last.
+func SortBySeverity(entries []*Entry) []*Entry {
+	slices.SortStableFunc(entries, func(a, b *Entry) int {
+		return cmp.Compare(a.Severity.Rank(), b.Severity.Rank())
+	})
+
+	return entries
+}
+
+// FilterByTags returns the entries that have all of the given tags.
+func FilterByTags(entries []*Entry, tags []string) []*Entry {
+	if len(tags) == 0 {
+		return entries
+	}
+
+	var results []*Entry
+
+	for _, entry := range entries {
+		if entry.HasTags(tags) {
+			results = append(results, entry)
+		}
+	}
+
+	return results
+}
+
+// resolve links and validates the loaded entries: aliases, extends, tag filters,
+// severities, includes, replacements, pipelines, delegation and executors.
+func (s *Store) resolve(cfg *Config) error {
+	err := s.addAliases()
+	if err != nil {
+		return err
+	}
+
+	err = s.resolveExtends()
+	if err != nil {
+		return err
+	}
+
+	s.applyInheritedFilters(cfg)
+	s.findUnmatchedFilters(cfg)
+	s.applySeverities(cfg)
+
+	err = s.resolveIncludes()
+	if err != nil {
+		return err
+	}
+
+	err = s.validateReplacements()
+	if err != nil {
+		return err
+	}
+
+	err = s.validatePipelines()
+	if err != nil {
+		return err
+	}
+
+	err = s.validateDelegation()
+	if err != nil {
+		return err
+	}
+
+	err = s.validateExecutors(cfg)
+	if err != nil {
+		return err
+	}
+
+	s.warnZeroTemperatures()
+
+	return nil
+}
+
 // loadFromFS loads entries from a filesystem into the store.
 // sourceName is used for error messages to identify the source.
 func (s *Store) loadFromFS(fsys fs.FS, sourceName string, cfg *Config) error {
@@ -184,7 +365,7 @@ func (s *Store) loadFromFS(fsys fs.FS, sourceName string, cfg *Config) error {
 			return walkErr
 		}
 
-		if d.IsDir() || !strings.HasSuffix(path, ".md") {
+		if d.IsDir() || !isSourceFile(path) {
 			return nil
 		}
 
@@ -193,61 +374,364 @@ func (s *Store) loadFromFS(fsys fs.FS, sourceName string, cfg *Config) error {
 			return fmt.Errorf("reading %s: %w", path, readErr)
 		}
 
-		entry, parseErr := parseMarkdown(data)
+		parsed, parseErr := parseFile(path, data)
 		if parseErr != nil {
-			return fmt.Errorf("parsing %s: %w", path, parseErr)
+			return fmt.Errorf("parsing %w", parseErr)
 		}
 
-		// Validate entry type
-		if !entry.Type.Valid() {
-			return fmt.Errorf("parsing %s: %w: %q", path, ErrInvalidType, entry.Type)
+		for _, p := range parsed {
+			loadErr := s.loadEntry(p, path, cfg)
+			if loadErr != nil {
+				return loadErr
+			}
 		}
 
-		// Validate entry (globs, etc.)
-		validateErr := entry.Validate()
-		if validateErr != nil {
-			return fmt.Errorf("validating %s: %w", path, validateErr)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", sourceName, err)
+	}
+
+	return nil
+}
+
+// loadEntry validates and names an entry parsed from the file at path, and adds
+// it to the store if the filters allow it. Errors for entries of bundle files
+// include the line the entry starts on.
+func (s *Store) loadEntry(p parsedEntry, path string, cfg *Config) error {
+	entry := p.entry
+
+	location := path
+	if p.name != "" {
+		location = fmt.Sprintf("%s:%d", path, p.line)
+	}
+
+	// Validate entry type
+	if !entry.Type.Valid() {
+		return fmt.Errorf("parsing %s: %w: %q", location, ErrInvalidType, entry.Type)
+	}
+
+	// Validate entry (globs, etc.)
+	err := entry.Validate()
+	if err != nil {
+		return fmt.Errorf("validating %s: %w", location, err)
+	}
+
+	// Derive name from path, stripping type prefix if present
+	entry.Name = deriveName(path, entry.Type)
+	if p.name != "" {
+		entry.Name += "/" + p.name
+	}
+
+	s.considered[entry.Type] = append(s.considered[entry.Type], entry)
+
+	// Check if entry is allowed by filter. Entries inheriting their tags are
+	// checked again once extends are resolved.
+	filter := cfg.FilterForType(entry.Type)
+	if !filter.IsAllowed(entry) && !entry.inheritsTags() {
+		return nil // Skip filtered entries
+	}
+
+	return s.add(entry, location)
+}
+
+// applyInheritedFilters removes the loaded entries that the filters reject
+// with their inherited tags.
+func (s *Store) applyInheritedFilters(cfg *Config) {
+	for _, typ := range []Type{TypeRule, TypeSkill, TypeInstruction, TypeAgent} {
+		filter := cfg.FilterForType(typ)
+
+		for _, entry := range s.List(typ) {
+			if !filter.IsAllowed(entry) {
+				delete(s.entries[typ], entry.Name)
+			}
 		}
+	}
+}
 
-		// Derive name from path, stripping type prefix if present
-		entry.Name = deriveName(path, entry.Type)
+// filterList is a configured list of patterns or tags and how they match entries.
+type filterList struct {
+	name     string
+	patterns []string
+	match    func(pattern string, e *Entry) bool
+}
 
-		// Check if entry is allowed by filter
-		filter := cfg.FilterForType(entry.Type)
-		if !filter.IsAllowed(entry.Name) {
-			return nil // Skip filtered entries
+// findUnmatchedFilters records the filter patterns and tags, and the rule
+// severity overrides, that match no entry read from the sources.
+func (s *Store) findUnmatchedFilters(cfg *Config) {
+	matchName := func(pattern string, e *Entry) bool { return matchesName(pattern, e.Name) }
+	matchTag := func(tag string, e *Entry) bool { return slices.Contains(e.Tags, tag) }
+
+	for _, typ := range []Type{TypeRule, TypeSkill, TypeInstruction, TypeAgent} {
+		filter := cfg.FilterForType(typ)
+		lists := []filterList{
+			{"allow", filter.Allow, matchName},
+			{"allow_tags", filter.AllowTags, matchTag},
+			{"block", filter.Block, matchName},
+			{"block_tags", filter.BlockTags, matchTag},
 		}
 
-		// Check for duplicates
-		if _, exists := s.entries[entry.Type]; !exists {
-			s.entries[entry.Type] = make(map[string]*Entry)
+		if typ == TypeRule {
+			lists = append(lists, filterList{"severity", slices.Sorted(maps.Keys(cfg.Rules.Severity)), matchName})
 		}
 
-		if _, exists := s.entries[entry.Type][entry.Name]; exists {
-			return fmt.Errorf("%s %q from %s: %w (already loaded)", entry.Type, entry.Name, sourceName, ErrDuplicate)
+		for _, list := range lists {
+			for _, pattern := range list.patterns {
+				matched := slices.ContainsFunc(s.considered[typ], func(e *Entry) bool {
+					return list.match(pattern, e)
+				})
+				if !matched {
+					s.unmatched = append(s.unmatched, UnmatchedFilter{List: string(typ) + "s." + list.name, Pattern: pattern})
+				}
+			}
 		}
+	}
+}
 
+// applySeverities sets the severity of each rule from the config overrides,
+// defaulting to should.
+func (s *Store) applySeverities(cfg *Config) {
+	for _, rule := range s.entries[TypeRule] {
+		rule.Severity = cmp.Or(cfg.Rules.SeverityFor(rule.Name), rule.Severity, SeverityShould)
+	}
+}
+
+// add adds a loaded entry to the store. An entry with the same name as a loaded
+// entry is a duplicate, unless one of them extends the other by name, in which
+// case the extending entry shadows the other.
+func (s *Store) add(entry *Entry, location string) error {
+	if _, exists := s.entries[entry.Type]; !exists {
+		s.entries[entry.Type] = make(map[string]*Entry)
+	}
+
+	existing, exists := s.entries[entry.Type][entry.Name]
+	if !exists {
 		s.entries[entry.Type][entry.Name] = entry
 
 		return nil
-	})
-	if err != nil {
-		return fmt.Errorf("loading %s: %w", sourceName, err)
+	}
+
+	_, shadowing := s.shadowed[entry.Type][entry.Name]
+	existingExtends := existing.Extends == entry.Name
+	entryExtends := entry.Extends == entry.Name
+
+	switch {
+	case !shadowing && existingExtends && !entryExtends:
+		s.shadow(entry)
+	case !shadowing && entryExtends && !existingExtends:
+		s.shadow(existing)
+		s.entries[entry.Type][entry.Name] = entry
+	default:
+		return fmt.Errorf("%s %q from %s: %w (already loaded)", entry.Type, entry.Name, location, ErrDuplicate)
+	}
+
+	return nil
+}
+
+// addAliases indexes the aliases of all loaded entries. An alias may not be
+// the name or alias of another entry of the same type.
+func (s *Store) addAliases() error {
+	for _, typ := range []Type{TypeRule, TypeSkill, TypeInstruction, TypeAgent} {
+		s.aliases[typ] = make(map[string]string)
+
+		for _, entry := range s.List(typ) {
+			for _, alias := range entry.Aliases {
+				if alias == entry.Name {
+					return fmt.Errorf("%s %q: %w: alias %q is its own name", typ, entry.Name, ErrInvalidDeprecation, alias)
+				}
+
+				other, exists := s.aliases[typ][alias]
+				if _, named := s.entries[typ][alias]; named {
+					exists, other = true, alias
+				}
+
+				if exists {
+					return fmt.Errorf("%s %q: alias %q: %w (already used by %q)", typ, entry.Name, alias, ErrDuplicate, other)
+				}
+
+				s.aliases[typ][alias] = entry.Name
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) shadow(entry *Entry) {
+	if _, exists := s.shadowed[entry.Type]; !exists {
+		s.shadowed[entry.Type] = make(map[string]*Entry)
+	}
+
+	s.shadowed[entry.Type][entry.Name] = entry
+}
+
+// validateReplacements checks that deprecated entries are replaced by entries
+// of the same type that are defined in the sources and not deprecated. A
+// replacement that was filtered out cannot be suggested, so it is dropped
+// with a warning.
+func (s *Store) validateReplacements() error {
+	for _, typ := range []Type{TypeRule, TypeSkill, TypeInstruction, TypeAgent} {
+		for _, entry := range s.List(typ) {
+			if entry.ReplacedBy == "" {
+				continue
+			}
+
+			replacement, err := s.Get(typ, entry.ReplacedBy)
+			if err != nil && s.filtered(typ, entry.ReplacedBy) != nil {
+				slog.Warn("replacement is filtered out", slog.String("type", string(typ)),
+					slog.String("name", entry.Name), slog.String("replacement", entry.ReplacedBy))
+
+				entry.ReplacedBy = ""
+
+				continue
+			}
+
+			if err != nil {
+				return fmt.Errorf("%s %q: %w: no source defines replacement %q",
+					typ, entry.Name, ErrInvalidDeprecation, entry.ReplacedBy)
+			}
+
+			if replacement.Deprecated {
+				return fmt.Errorf("%s %q: %w: replacement %q is deprecated",
+					typ, entry.Name, ErrInvalidDeprecation, entry.ReplacedBy)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validatePipelines checks that pipeline steps reference agents defined in the
+// sources that are not pipelines themselves. A pipeline with a step agent that
+// was filtered out cannot run as defined, so it is dropped with a warning.
+func (s *Store) validatePipelines() error {
+	for _, agent := range s.List(TypeAgent) {
+		err := s.validatePipeline(agent)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+func (s *Store) validatePipeline(agent *Entry) error {
+	for i, step := range agent.Steps {
+		for _, name := range step.Agents {
+			ref, err := s.Get(TypeAgent, name)
+
+			loaded := err == nil
+			if !loaded {
+				ref = s.filtered(TypeAgent, name)
+			}
+
+			switch {
+			case ref == nil:
+				return fmt.Errorf("agent %q step %d: %w: unknown agent %q", agent.Name, i+1, ErrInvalidPipeline, name)
+			case ref.IsPipeline():
+				return fmt.Errorf("agent %q step %d: %w: nested pipeline %q", agent.Name, i+1, ErrInvalidPipeline, name)
+			case !loaded:
+				slog.Warn("pipeline dropped: step agent is filtered out",
+					slog.String("pipeline", agent.Name), slog.String("agent", name))
+				delete(s.entries[TypeAgent], agent.Name)
+
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateDelegation checks that agents referenced by skills are defined in the
+// sources. Agents that were filtered out are removed from the skill with a warning,
+// so the skill delegates only to the agents that are loaded.
+func (s *Store) validateDelegation() error {
+	for _, skill := range s.List(TypeSkill) {
+		for _, name := range skill.Agents {
+			_, err := s.Get(TypeAgent, name)
+			if err != nil && s.filtered(TypeAgent, name) == nil {
+				return fmt.Errorf("skill %q: %w %q", skill.Name, ErrUnknownAgent, name)
+			}
+		}
+
+		skill.Agents = slices.DeleteFunc(skill.Agents, func(name string) bool {
+			if _, err := s.Get(TypeAgent, name); err == nil {
+				return false
+			}
+
+			slog.Warn("skill agent is filtered out", slog.String("skill", skill.Name), slog.String("agent", name))
+
+			return true
+		})
+	}
+
+	return nil
+}
+
+// validateExecutors checks that agents pinned to the local executor have one configured.
+func (s *Store) validateExecutors(cfg *Config) error {
+	if cfg.Execution.Local != nil {
+		return nil
+	}
+
+	for _, agent := range s.List(TypeAgent) {
+		if agent.Executor == ExecutorLocal {
+			return fmt.Errorf("agent %q: %w: execution.local is not configured", agent.Name, ErrInvalidExecutor)
+		}
+	}
+
+	return nil
+}
+
+// warnZeroTemperatures warns about agents with a temperature of 0 that may run
+// through MCP sampling, which cannot send it, so the client's default applies.
+func (s *Store) warnZeroTemperatures() {
+	for _, agent := range s.List(TypeAgent) {
+		if agent.Executor == ExecutorLocal || agent.Sampling == nil || agent.Sampling.Temperature == nil {
+			continue
+		}
+
+		if *agent.Sampling.Temperature == 0 {
+			slog.Warn("temperature 0 is not sent in sampling requests, the client's default applies",
+				slog.String("agent", agent.Name))
+		}
+	}
+}
+
+// filtered returns the entry of the given type with the given name or alias that
+// was read from the sources but is not loaded, e.g. because a filter removed it.
+// Returns nil if no source defines it or it is loaded.
+func (s *Store) filtered(typ Type, name string) *Entry {
+	if _, err := s.Get(typ, name); err == nil {
+		return nil
+	}
+
+	i := slices.IndexFunc(s.considered[typ], func(e *Entry) bool {
+		return e.Name == name || slices.Contains(e.Aliases, name)
+	})
+	if i < 0 {
+		return nil
+	}
+
+	return s.considered[typ][i]
+}
+
 // deriveName extracts the entry name from the file path.
-// It removes the .md extension and strips type-based prefixes (e.g., "rules/", "skills/").
+// It removes the file extension, and the ".bundle" suffix of bundle files, and
+// strips type-based prefixes (e.g., "rules/", "skills/").
 // If no recognized prefix is found, the full relative path is preserved (minus extension).
 // Examples:
 //   - "rules/go/error-assignment.md" -> "go/error-assignment"
 //   - "skills/refactor.md" -> "refactor"
 //   - "custom/go/my-rule.md" -> "custom/go/my-rule"
 //   - "my-rule.md" -> "my-rule"
+//   - "rules/go.bundle.yaml" -> "go" (bundle entries are named "go/<name>")
 func deriveName(path string, typ Type) string {
-	name := strings.TrimSuffix(path, ".md")
+	name := strings.TrimSuffix(path, filepath.Ext(path))
+	if isBundleFile(path) {
+		name = strings.TrimSuffix(name, bundleSuffix)
+	}
 
 	prefixes := []string{
 		string(typ) + "s/", // "rules/", "skills/"
@@ -278,6 +762,10 @@ func matchesQuery(entry *Entry, query string) bool {
 		return true
 	}
 
+	if slices.Contains(entry.Tags, query) {
+		return true
+	}
+
 	return strings.Contains(strings.ToLower(entry.Body), query)
 }
 
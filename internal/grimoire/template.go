@@ -0,0 +1,240 @@
+package grimoire
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// argFuncName is the template function returning an argument's value by name,
+// for arguments whose names are not template identifiers (e.g., {{arg "focus-area"}}).
+const argFuncName = "arg"
+
+// literalDelimHint tells authors how to write a literal "{{" in a template body.
+const literalDelimHint = `bodies of entries with arguments are templates, write {{"{{"}} for a literal "{{"`
+
+// argumentNamePattern matches valid argument names.
+var argumentNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// identifierPattern matches argument names usable as template functions.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// templateFuncs are the helpers available in entry bodies, in addition to one
+// function per argument that returns its typed value (e.g., {{focus}}).
+var templateFuncs = template.FuncMap{
+	"default": defaultValue,
+	"list":    listValue,
+	"join":    joinList,
+	"quote":   strconv.Quote,
+	"code":    inlineCode,
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"trim":    strings.TrimSpace,
+}
+
+// reservedNames cannot be used as argument names: they are template keywords
+// or built-in functions.
+var reservedNames = []string{
+	"and", "block", "break", "call", "continue", "define", "else", "end", "eq", "false",
+	"ge", "gt", "html", "if", "index", "js", "le", "len", "lt", "ne", "nil", "not", "or",
+	"print", "printf", "println", "range", "slice", "template", "true", "urlquery", "with",
+}
+
+// parseTemplate parses the body as a template over the entry's arguments.
+// Entries without arguments are not templates, so their bodies may contain "{{".
+// Placeholders of arguments whose names are not identifiers, such as
+// {{focus-area}}, are rewritten to {{arg "focus-area"}} first.
+func (e *Entry) parseTemplate() error {
+	if len(e.Arguments) == 0 {
+		return nil
+	}
+
+	tmpl, err := template.New("body").
+		Funcs(templateFuncs).
+		Funcs(argumentFuncs(e.Arguments, nil)).
+		Parse(rewritePlaceholders(e.Body, e.Arguments))
+	if err != nil {
+		return fmt.Errorf("%w: %w (%s)", ErrInvalidTemplate, err, literalDelimHint)
+	}
+
+	e.template = tmpl
+
+	return nil
+}
+
+// rewritePlaceholders rewrites {{name}} placeholders of arguments whose names
+// are not template identifiers to calls of the arg function.
+func rewritePlaceholders(body string, args []Argument) string {
+	for _, arg := range args {
+		if identifierPattern.MatchString(arg.Name) {
+			continue
+		}
+
+		placeholder := regexp.MustCompile(`\{\{(-?)\s*` + regexp.QuoteMeta(arg.Name) + `\s*(-?)\}\}`)
+		body = placeholder.ReplaceAllString(body, `{{$1 `+argFuncName+` `+strconv.Quote(arg.Name)+` $2}}`)
+	}
+
+	return body
+}
+
+// validateArgumentName checks that name can be referenced from a template.
+func validateArgumentName(name string) error {
+	if !argumentNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q must be a letter or underscore followed by letters, digits, underscores or hyphens",
+			ErrInvalidArgument, name)
+	}
+
+	if slices.Contains(reservedNames, name) || templateFuncs[name] != nil || name == argFuncName {
+		return fmt.Errorf("%w: %q is a reserved template name", ErrInvalidArgument, name)
+	}
+
+	return nil
+}
+
+// ArgumentValues returns values with defaults applied to arguments without a value.
+func (e *Entry) ArgumentValues(values map[string]string) map[string]string {
+	result := make(map[string]string, len(e.Arguments))
+	maps.Copy(result, values)
+
+	for _, arg := range e.Arguments {
+		if result[arg.Name] == "" && arg.Default != "" {
+			result[arg.Name] = arg.Default
+		}
+	}
+
+	return result
+}
+
+// RenderBody renders the body template with the given argument values,
+// applying defaults. Values are converted to their argument's type; arguments
+// without a value are the type's zero value (e.g., an empty string).
+// Bodies of entries without arguments are returned unchanged.
+func (e *Entry) RenderBody(values map[string]string) (string, error) {
+	if e.template == nil {
+		return e.Body, nil
+	}
+
+	values = e.ArgumentValues(values)
+	typed := make(map[string]any, len(e.Arguments))
+
+	for i := range e.Arguments {
+		arg := &e.Arguments[i]
+
+		value, err := arg.typedValue(values[arg.Name])
+		if err != nil {
+			return "", fmt.Errorf("%s %q: %w: %w", e.Type, e.Name, ErrRender, err)
+		}
+
+		typed[arg.Name] = value
+	}
+
+	tmpl, err := e.template.Clone()
+	if err != nil {
+		return "", fmt.Errorf("%s %q: %w: %w", e.Type, e.Name, ErrRender, err)
+	}
+
+	var b strings.Builder
+
+	err = tmpl.Funcs(argumentFuncs(e.Arguments, typed)).Execute(&b, typed)
+	if err != nil {
+		return "", fmt.Errorf("%s %q: %w: %w", e.Type, e.Name, ErrRender, err)
+	}
+
+	return b.String(), nil
+}
+
+// argumentFuncs returns one template function per argument whose name is an
+// identifier, returning its value, and the arg function for all arguments.
+func argumentFuncs(args []Argument, values map[string]any) template.FuncMap {
+	funcs := make(template.FuncMap, len(args)+1)
+
+	for _, arg := range args {
+		if !identifierPattern.MatchString(arg.Name) {
+			continue
+		}
+
+		value := values[arg.Name]
+		funcs[arg.Name] = func() any { return value }
+	}
+
+	funcs[argFuncName] = func(name string) (any, error) {
+		if !slices.ContainsFunc(args, func(a Argument) bool { return a.Name == name }) {
+			return nil, fmt.Errorf("%w: unknown argument %q", ErrInvalidArgument, name)
+		}
+
+		return values[name], nil
+	}
+
+	return funcs
+}
+
+// defaultValue returns value, or fallback if value is empty or false.
+// Used as {{focus | default "everything"}}.
+func defaultValue(fallback, value any) any {
+	if truth, _ := template.IsTrue(value); !truth {
+		return fallback
+	}
+
+	return value
+}
+
+// listValue returns list arguments as they are and splits other values.
+// Used as {{range list files}}.
+func listValue(value any) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case nil:
+		return nil
+	default:
+		return splitList(fmt.Sprint(v))
+	}
+}
+
+// splitList splits a list value on commas and newlines, dropping empty items.
+func splitList(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' })
+
+	items := make([]string, 0, len(fields))
+
+	for _, field := range fields {
+		if item := strings.TrimSpace(field); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// joinList joins items with sep. Used as {{list files | join ", "}}.
+func joinList(sep string, items []string) string {
+	return strings.Join(items, sep)
+}
+
+// inlineCode renders value as markdown inline code, using a backtick fence
+// longer than any run of backticks inside it.
+func inlineCode(value string) string {
+	longest, run := 0, 0
+
+	for _, r := range value {
+		if r != '`' {
+			run = 0
+
+			continue
+		}
+
+		run++
+		longest = max(longest, run)
+	}
+
+	fence := strings.Repeat("`", longest+1)
+	if longest > 0 {
+		return fence + " " + value + " " + fence
+	}
+
+	return fence + value + fence
+}
@@ -0,0 +1,172 @@
+package grimoire
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRewritePlaceholders(t *testing.T) {
+	t.Parallel()
+
+	args := []Argument{{Name: "focus-area"}, {Name: "target"}}
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"hyphenated placeholder", "Review {{focus-area}}.", `Review {{ arg "focus-area" }}.`},
+		{"spaces", "{{ focus-area }}", `{{ arg "focus-area" }}`},
+		{"trim markers", "a {{- focus-area -}} b", `a {{- arg "focus-area" -}} b`},
+		{"identifiers unchanged", "{{target}}", "{{target}}"},
+		{"other actions unchanged", `{{if arg "focus-area"}}x{{end}}`, `{{if arg "focus-area"}}x{{end}}`},
+		{"longer names unchanged", "{{focus-area-2}}", "{{focus-area-2}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := rewritePlaceholders(tt.body, args)
+			if got != tt.want {
+				t.Errorf("rewritePlaceholders(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderBody(t *testing.T) {
+	t.Parallel()
+
+	args := []Argument{
+		{Name: "focus", Default: "everything"},
+		{Name: "focus-area"},
+		{Name: "files", Type: "list"},
+		{Name: "draft", Type: "boolean"},
+		{Name: "count", Type: "integer"},
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		values map[string]string
+		want   string
+	}{
+		{"value", "Review {{focus}}.", map[string]string{"focus": "errors"}, "Review errors."},
+		{"default", "Review {{focus}}.", nil, "Review everything."},
+		{"hyphenated name", "In {{focus-area}}.", map[string]string{"focus-area": "api"}, "In api."},
+		{"arg function", `{{if arg "focus-area"}}set{{else}}unset{{end}}`, nil, "unset"},
+		{"missing value is empty", "[{{focus-area}}]", nil, "[]"},
+		{"boolean", "{{if draft}}draft{{else}}final{{end}}", map[string]string{"draft": "false"}, "final"},
+		{"integer", "{{if gt count 2}}many{{end}}", map[string]string{"count": "3"}, "many"},
+		{"list", "{{range files}}- {{.}}\n{{end}}", map[string]string{"files": "a.go, b.go"}, "- a.go\n- b.go\n"},
+		{"join", `{{list files | join " "}}`, map[string]string{"files": "a\nb"}, "a b"},
+		{"default function", `{{arg "focus-area" | default "all"}}`, nil, "all"},
+		{"code", "{{code focus}}", map[string]string{"focus": "a`b"}, "`` a`b ``"},
+		{"literal braces", `{{"{{"}} x }}`, nil, "{{ x }}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			entry := &Entry{Type: TypeSkill, Name: "test", Arguments: args, Body: tt.body}
+
+			err := entry.parseTemplate()
+			if err != nil {
+				t.Fatalf("parseTemplate() error = %v", err)
+			}
+
+			got, err := entry.RenderBody(tt.values)
+			if err != nil {
+				t.Fatalf("RenderBody() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("RenderBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderBodyWithoutArguments(t *testing.T) {
+	t.Parallel()
+
+	entry := &Entry{Type: TypeRule, Name: "test", Body: "Use ${{ secrets.TOKEN }}."}
+
+	err := entry.parseTemplate()
+	if err != nil {
+		t.Fatalf("parseTemplate() error = %v", err)
+	}
+
+	got, err := entry.RenderBody(nil)
+	if err != nil || got != entry.Body {
+		t.Errorf("RenderBody() = %q, %v, want body unchanged", got, err)
+	}
+}
+
+func TestParseTemplateErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"literal braces", "Use ${{ secrets.TOKEN }}."},
+		{"unclosed action", "{{if focus}}x"},
+		{"unknown function", "{{nope}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			entry := &Entry{Type: TypeSkill, Name: "test", Arguments: []Argument{{Name: "focus"}}, Body: tt.body}
+
+			err := entry.parseTemplate()
+			if !errors.Is(err, ErrInvalidTemplate) {
+				t.Fatalf("parseTemplate() error = %v, want %v", err, ErrInvalidTemplate)
+			}
+
+			if !strings.Contains(err.Error(), `{{"{{"}}`) {
+				t.Errorf("parseTemplate() error = %q, want a hint on literal braces", err)
+			}
+		})
+	}
+}
+
+func TestValidateArgumentName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"focus", true},
+		{"focus_area", true},
+		{"focus-area", true},
+		{"_private", true},
+		{"1st", false},
+		{"-focus", false},
+		{"focus area", false},
+		{"if", false},
+		{"default", false},
+		{"arg", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateArgumentName(tt.name)
+			if (err == nil) != tt.valid {
+				t.Errorf("validateArgumentName(%q) = %v, want valid %v", tt.name, err, tt.valid)
+			}
+
+			if err != nil && !errors.Is(err, ErrInvalidArgument) {
+				t.Errorf("validateArgumentName(%q) = %v, want %v", tt.name, err, ErrInvalidArgument)
+			}
+		})
+	}
+}
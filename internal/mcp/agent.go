@@ -2,34 +2,99 @@ package mcp
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/monke/grimoire/internal/grimoire"
 )
 
-var errUnexpectedContentType = errors.New("unexpected content type in sampling result")
-
 // agentInput is the input schema for the agent tool.
 type agentInput struct {
-	Names   []string `json:"names"             jsonschema:"Agent names to execute"`
+	Names   []string `json:"names,omitempty"   jsonschema:"Agent names to execute"`
+	Skill   string   `json:"skill,omitempty"   jsonschema:"Skill whose agents to execute with the skill as context"`
 	Context string   `json:"context,omitempty" jsonschema:"Context provided to agents"`
+
+	Arguments      map[string]map[string]string `json:"arguments,omitempty"       jsonschema:"Argument values per agent name"`
+	SkillArguments map[string]string            `json:"skill_arguments,omitempty" jsonschema:"Argument values for the skill"`
+
+	Resources []string `json:"resources,omitempty" jsonschema:"Grimoire resource URIs to embed as context (e.g. grimoire://rules/go/errors)"`
+	Files     []string `json:"files,omitempty"     jsonschema:"File paths relative to a client root to embed as context; images and audio are sent as media"`
+
+	Transcript bool `json:"transcript,omitempty" jsonschema:"Return each agent's conversation for debugging"`
+}
+
+// agentPlan is a resolved agent tool call: what to run and with which inputs.
+type agentPlan struct {
+	agents      []*grimoire.Entry
+	userContext string
+
+	// attachments are sent to every agent before its prompt.
+	attachments []*mcp.SamplingMessage
+
+	// arguments holds the resolved argument values per agent name,
+	// including agents run by pipeline steps.
+	arguments map[string]map[string]string
 }
 
 // agentResult holds the output from a single agent execution.
+// For pipelines, Steps holds the results of each step in order.
 type agentResult struct {
-	Name   string
-	Output string
-	Error  error
+	Name     string
+	Output   string
+	Data     any
+	Media    mcp.Content
+	Error    error
+	Duration time.Duration
+	Attempts int
+	TimedOut bool
+	Steps    [][]agentResult
+
+	// Transcript is the conversation of a successful agent.
+	Transcript []*mcp.SamplingMessage
+}
+
+// agentOutput is the structured output of the agent tool.
+type agentOutput struct {
+	Results  []agentResultOutput `json:"results,omitempty"  jsonschema:"Agent results in request order"`
+	Findings []map[string]any    `json:"findings,omitempty" jsonschema:"Findings from structured agent outputs, most severe first"`
+}
+
+// agentResultOutput is the serializable form of agentResult.
+type agentResultOutput struct {
+	Name       string `json:"name"             jsonschema:"Agent name"`
+	Output     string `json:"output,omitempty" jsonschema:"Agent response text"`
+	Data       any    `json:"data,omitempty"   jsonschema:"Validated JSON output for agents with an output schema"`
+	MediaType  string `json:"media_type,omitempty" jsonschema:"MIME type of image or audio output, returned as tool content"`
+	Error      string `json:"error,omitempty"  jsonschema:"Error message if the agent failed"`
+	DurationMS int64  `json:"duration_ms"      jsonschema:"Execution time in milliseconds"`
+	Attempts   int    `json:"attempts"         jsonschema:"Number of execution attempts"`
+	TimedOut   bool   `json:"timed_out"        jsonschema:"Whether the agent exceeded its timeout"`
+
+	Steps      []agentStepOutput `json:"steps,omitempty"      jsonschema:"Per-step agent results for pipelines"`
+	Transcript []transcriptEntry `json:"transcript,omitempty" jsonschema:"Agent conversation, if requested"`
+}
+
+// agentStepOutput is the result of one agent within a pipeline step.
+type agentStepOutput struct {
+	Step       int    `json:"step"             jsonschema:"Step number, starting at 1"`
+	Name       string `json:"name"             jsonschema:"Agent name"`
+	Output     string `json:"output,omitempty" jsonschema:"Agent response text"`
+	Data       any    `json:"data,omitempty"   jsonschema:"Validated JSON output for agents with an output schema"`
+	Error      string `json:"error,omitempty"  jsonschema:"Error message if the agent failed"`
+	DurationMS int64  `json:"duration_ms"      jsonschema:"Execution time in milliseconds"`
+	TimedOut   bool   `json:"timed_out"        jsonschema:"Whether the agent exceeded its timeout"`
 }
 
 func (s *Server) registerAgent() {
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "agent",
+		Title:       "Run Agents",
+		Annotations: samplingAnnotations("Run Agents"),
+		Icons:       toolIcons("agent"),
 		Description: grimoire.BuildAgentDescription(s.store),
 	}, s.handleAgent)
 }
@@ -38,86 +103,174 @@ func (s *Server) handleAgent(
 	ctx context.Context,
 	req *mcp.CallToolRequest,
 	input agentInput,
-) (*mcp.CallToolResult, any, error) {
-	slog.DebugContext(ctx, "agent tool called", slog.Any("names", input.Names))
+) (*mcp.CallToolResult, *agentOutput, error) {
+	slog.DebugContext(ctx, "agent tool called", slog.Any("names", input.Names), slog.String("skill", input.Skill))
+
+	if len(input.Names) == 0 && input.Skill == "" {
+		return errorResultMsg("provide names or skill parameter"), nil, nil
+	}
+
+	if !supportsSampling(req.Session) {
+		if s.local == nil {
+			slog.WarnContext(ctx, "client does not support sampling")
+
+			return s.samplingNotSupported(), nil, nil
+		}
+
+		slog.InfoContext(ctx, "client does not support sampling, using local executor")
+	}
 
-	if len(input.Names) == 0 {
-		return errorResultMsg("names parameter is required"), nil, nil
+	plan, errResult := s.planAgents(ctx, req.Session, input)
+	if errResult != nil {
+		return errResult, nil, nil
 	}
 
-	params := req.Session.InitializeParams()
-	if params == nil || params.Capabilities == nil || params.Capabilities.Sampling == nil {
-		slog.WarnContext(ctx, "client does not support sampling")
+	slog.DebugContext(ctx, "executing agents", slog.Int("count", len(plan.agents)))
+
+	run := s.newAgentRun(req, plan)
+	results := s.runAgents(ctx, run, plan.agents, plan.userContext)
+
+	result, output := s.formatAgentResults(results, input.Transcript)
+
+	return result, output, nil
+}
+
+// planAgents resolves the agents to execute, the context to give them and their arguments.
+// In skill mode, the skill's agents are added and its rendered body becomes context.
+// Missing required agent arguments are elicited before anything is sampled.
+// Returns a tool error result if the input cannot be resolved.
+func (s *Server) planAgents(
+	ctx context.Context,
+	session *mcp.ServerSession,
+	input agentInput,
+) (*agentPlan, *mcp.CallToolResult) {
+	names := input.Names
+	userContext := input.Context
+
+	if input.Skill != "" {
+		skill, err := s.store.Get(grimoire.TypeSkill, input.Skill)
+		if err != nil {
+			slog.WarnContext(ctx, "skill not found", slog.String("name", input.Skill))
+
+			return nil, errorResultMsg("skill not found: " + input.Skill)
+		}
+
+		if len(skill.Agents) == 0 {
+			return nil, errorResultMsg("skill has no agents: " + skill.Name)
+		}
+
+		values, err := s.resolveArguments(ctx, session, skill, input.SkillArguments)
+		if err != nil {
+			return nil, errorResult(err)
+		}
+
+		body, err := skill.RenderBody(values)
+		if err != nil {
+			return nil, errorResult(err)
+		}
 
-		return s.samplingNotSupported(), nil, nil
+		names = append(names, skill.Agents...)
+		userContext = skillContext(body, input.Context)
 	}
 
-	var agents []*grimoire.Entry
+	agents := make([]*grimoire.Entry, 0, len(names))
 
-	for _, name := range input.Names {
+	for _, name := range names {
 		entry, err := s.store.Get(grimoire.TypeAgent, name)
 		if err != nil {
 			slog.WarnContext(ctx, "agent not found", slog.String("name", name))
 
-			//nolint:nilerr // return tool error, not Go error
-			return errorResultMsg("agent not found: " + name), nil, nil
+			return nil, errorResultMsg("agent not found: " + name)
 		}
 
 		agents = append(agents, entry)
 	}
 
-	slog.DebugContext(ctx, "executing agents", slog.Int("count", len(agents)))
+	attachments, err := s.attachments(ctx, session, input.Resources, input.Files)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to resolve agent attachments", slog.Any("error", err))
 
-	var results []agentResult
+		return nil, errorResult(err)
+	}
 
-	for _, agent := range agents {
-		slog.DebugContext(ctx, "executing agent", slog.String("name", agent.Name))
+	plan := &agentPlan{
+		agents:      agents,
+		userContext: userContext,
+		attachments: attachments,
+		arguments:   make(map[string]map[string]string),
+	}
 
-		output, err := s.executeSampling(ctx, req.Session, agent, input.Context)
-		results = append(results, agentResult{
-			Name:   agent.Name,
-			Output: output,
-			Error:  err,
-		})
+	for _, agent := range s.samplingAgents(agents) {
+		if _, done := plan.arguments[agent.Name]; done {
+			continue
+		}
 
+		values, err := s.resolveArguments(ctx, session, agent, input.Arguments[agent.Name])
 		if err != nil {
-			slog.WarnContext(ctx, "agent execution failed",
-				slog.String("name", agent.Name), slog.Any("error", err))
-		} else {
-			slog.DebugContext(ctx, "agent execution completed", slog.String("name", agent.Name))
+			return nil, errorResult(err)
 		}
+
+		plan.arguments[agent.Name] = values
 	}
 
-	return s.formatAgentResults(results), nil, nil
+	// Agents requested by multi-turn agents get their arguments as given.
+	for name, values := range input.Arguments {
+		if _, done := plan.arguments[name]; !done {
+			plan.arguments[name] = values
+		}
+	}
+
+	return plan, nil
 }
 
-func (s *Server) executeSampling(
-	ctx context.Context,
-	session *mcp.ServerSession,
-	agent *grimoire.Entry,
-	context string,
-) (string, error) {
-	prompt := agent.Body
-	if context != "" {
-		prompt = prompt + "\n\n## Context\n" + context
-	}
-
-	result, err := session.CreateMessage(ctx, &mcp.CreateMessageParams{
-		Messages: []*mcp.SamplingMessage{{
-			Role:    "user",
-			Content: &mcp.TextContent{Text: prompt},
-		}},
-		SystemPrompt: agent.Description,
-	})
-	if err != nil {
-		return "", fmt.Errorf("sampling failed: %w", err)
+// samplingAgents expands pipelines into the agents run by their steps.
+// Agents appear once per execution, so duplicates are kept.
+func (s *Server) samplingAgents(agents []*grimoire.Entry) []*grimoire.Entry {
+	var result []*grimoire.Entry
+
+	for _, agent := range agents {
+		if !agent.IsPipeline() {
+			result = append(result, agent)
+
+			continue
+		}
+
+		for _, step := range agent.Steps {
+			for _, name := range step.Agents {
+				ref, err := s.store.Get(grimoire.TypeAgent, name)
+				if err == nil {
+					result = append(result, ref)
+				}
+			}
+		}
 	}
 
-	if tc, ok := result.Content.(*mcp.TextContent); ok {
-		return tc.Text, nil
+	return result
+}
+
+// agentPrompt appends the user-provided context to an agent's rendered body.
+func agentPrompt(body, userContext string) string {
+	if userContext == "" {
+		return body
 	}
 
-	return "", errUnexpectedContentType
+	return body + "\n\n## Context\n" + userContext
+}
+
+// skillContext combines a skill's rendered body with the user-provided context.
+func skillContext(skillBody, userContext string) string {
+	if userContext == "" {
+		return skillBody
+	}
+
+	return skillBody + "\n\n" + userContext
+}
+
+// supportsSampling reports whether the client declared the sampling capability.
+func supportsSampling(session *mcp.ServerSession) bool {
+	params := session.InitializeParams()
+
+	return params != nil && params.Capabilities != nil && params.Capabilities.Sampling != nil
 }
 
 func (s *Server) samplingNotSupported() *mcp.CallToolResult {
@@ -130,29 +283,65 @@ func (s *Server) samplingNotSupported() *mcp.CallToolResult {
 This feature requires the sampling capability to spawn subagent prompts.
 Claude Code support is tracked at: https://github.com/anthropics/claude-code/issues/1785
 
-Alternative: Use guidance(name: "agent-name") to load the prompt and run it manually.`,
+Alternatives:
+- Configure execution.local to run agents on an OpenAI-compatible endpoint.
+- Use guidance(name: "agent-name") to load the prompt and run it manually.`,
 			},
 		},
 	}
 }
 
-func (s *Server) formatAgentResults(results []agentResult) *mcp.CallToolResult {
+func (s *Server) formatAgentResults(results []agentResult, withTranscript bool) (*mcp.CallToolResult, *agentOutput) {
 	var b strings.Builder
 
+	output := &agentOutput{Results: make([]agentResultOutput, len(results))}
+
+	var media []mcp.Content
+
 	for i, r := range results {
 		if i > 0 {
 			b.WriteString("\n\n---\n\n")
 		}
 
-		fmt.Fprintf(&b, "## %s\n\n", r.Name)
+		fmt.Fprintf(&b, "## %s (%s)\n\n", r.Name, r.Duration.Round(time.Millisecond))
 
-		if r.Error != nil {
+		output.Results[i] = agentResultOutput{
+			Name:       r.Name,
+			Output:     r.Output,
+			Data:       r.Data,
+			DurationMS: r.Duration.Milliseconds(),
+			Attempts:   r.Attempts,
+			TimedOut:   r.TimedOut,
+		}
+
+		switch {
+		case r.TimedOut:
+			fmt.Fprintf(&b, "**Timeout**: %s\n", r.Error.Error())
+			output.Results[i].Error = r.Error.Error()
+		case r.Error != nil:
 			fmt.Fprintf(&b, "**Error**: %s\n", r.Error.Error())
-		} else {
+			output.Results[i].Error = r.Error.Error()
+		case r.Media != nil:
+			mediaType := mediaType(r.Media)
+			fmt.Fprintf(&b, "_%s output, attached as content %d_\n", mediaType, len(media)+2)
+			output.Results[i].MediaType = mediaType
+			media = append(media, r.Media)
+		default:
 			b.WriteString(r.Output)
 		}
+
+		if len(r.Steps) > 0 {
+			writeSteps(&b, r.Steps)
+			output.Results[i].Steps = stepOutputs(r.Steps)
+		}
+
+		if withTranscript {
+			output.Results[i].Transcript = transcript(r.Transcript)
+		}
 	}
 
+	output.Findings = collectFindings(results)
+
 	hasErrors := false
 
 	for _, r := range results {
@@ -165,8 +354,18 @@ func (s *Server) formatAgentResults(results []agentResult) *mcp.CallToolResult {
 
 	return &mcp.CallToolResult{
 		IsError: hasErrors,
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: b.String()},
-		},
+		Content: append([]mcp.Content{&mcp.TextContent{Text: b.String()}}, media...),
+	}, output
+}
+
+// mediaType returns the MIME type of image or audio content.
+func mediaType(content mcp.Content) string {
+	switch c := content.(type) {
+	case *mcp.ImageContent:
+		return c.MIMEType
+	case *mcp.AudioContent:
+		return c.MIMEType
+	default:
+		return ""
 	}
 }
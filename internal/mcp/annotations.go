@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"embed"
+	"encoding/base64"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// iconsFS contains the SVG icons advertised for each tool.
+//
+//go:embed icons/*.svg
+var iconsFS embed.FS
+
+// readOnlyAnnotations marks a tool that only reads grimoire content.
+// Clients may auto-approve such tools.
+func readOnlyAnnotations(title string) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:          title,
+		ReadOnlyHint:   true,
+		IdempotentHint: true,
+		OpenWorldHint:  boolPtr(false),
+	}
+}
+
+// samplingAnnotations marks a tool that calls the client's model.
+// Results differ between calls, so the tool is neither read-only nor idempotent.
+func samplingAnnotations(title string) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:           title,
+		DestructiveHint: boolPtr(false),
+		OpenWorldHint:   boolPtr(true),
+	}
+}
+
+// toolIcons returns the icon for the named tool as an SVG data URI.
+// Returns nil if no icon exists for the tool.
+func toolIcons(name string) []mcp.Icon {
+	data, err := iconsFS.ReadFile("icons/" + name + ".svg")
+	if err != nil {
+		slog.Debug("no icon for tool", slog.String("name", name))
+
+		return nil
+	}
+
+	return []mcp.Icon{{
+		Source:   "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(data),
+		MIMEType: "image/svg+xml",
+		Sizes:    []string{"any"},
+	}}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,184 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/monke/grimoire/internal/grimoire"
+)
+
+// maxAttachmentSize limits the size of a file embedded in agent context.
+const maxAttachmentSize = 4 << 20
+
+var (
+	errRootsUnsupported = errors.New("client does not support roots")
+	errFileNotFound     = errors.New("file not found in client roots")
+	errFileTooLarge     = errors.New("file too large")
+	errBinaryFile       = errors.New("unsupported binary file")
+	errUnknownResource  = errors.New("unknown resource")
+)
+
+// attachments resolves grimoire resource URIs and files under the client's roots
+// into sampling messages that are sent to every agent before its prompt.
+func (s *Server) attachments(
+	ctx context.Context,
+	session *mcp.ServerSession,
+	resources, files []string,
+) ([]*mcp.SamplingMessage, error) {
+	messages := make([]*mcp.SamplingMessage, 0, len(resources)+len(files))
+
+	for _, uri := range resources {
+		entry, err := s.resourceEntry(uri)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := entry.RenderBody(nil)
+		if err != nil {
+			return nil, fmt.Errorf("resource %q: %w", uri, err)
+		}
+
+		messages = append(messages, &mcp.SamplingMessage{
+			Role:    "user",
+			Content: &mcp.TextContent{Text: "## Resource: " + uri + "\n\n" + body},
+		})
+	}
+
+	if len(files) == 0 {
+		return messages, nil
+	}
+
+	roots, err := rootDirs(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range files {
+		content, err := readAttachment(roots, name)
+		if err != nil {
+			return nil, fmt.Errorf("file %q: %w", name, err)
+		}
+
+		messages = append(messages, &mcp.SamplingMessage{Role: "user", Content: content})
+	}
+
+	return messages, nil
+}
+
+// resourceEntry returns the entry served by a grimoire resource URI.
+func (s *Server) resourceEntry(uri string) (*grimoire.Entry, error) {
+	for template, typ := range resourceTemplateTypes {
+		prefix := strings.TrimSuffix(template, "{name}")
+		if !strings.HasPrefix(uri, prefix) {
+			continue
+		}
+
+		name, err := extractResourceName(uri, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("resource %q: %w", uri, err)
+		}
+
+		entry, err := s.store.Get(typ, name)
+		if err != nil {
+			return nil, fmt.Errorf("resource %q: %w", uri, err)
+		}
+
+		return entry, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", errUnknownResource, uri)
+}
+
+// rootDirs lists the local directories of the client's file:// roots.
+func rootDirs(ctx context.Context, session *mcp.ServerSession) ([]string, error) {
+	params := session.InitializeParams()
+	if params == nil || params.Capabilities == nil || params.Capabilities.RootsV2 == nil {
+		return nil, errRootsUnsupported
+	}
+
+	result, err := session.ListRoots(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing roots: %w", err)
+	}
+
+	var dirs []string
+
+	for _, root := range result.Roots {
+		if dir, ok := rootPath(root.URI); ok {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs, nil
+}
+
+// readAttachment reads a file relative to the first root that contains it.
+// Images and audio become media content; other files must be UTF-8 text.
+// Paths cannot escape their root.
+func readAttachment(roots []string, name string) (mcp.Content, error) {
+	for _, dir := range roots {
+		data, err := readRootFile(dir, filepath.FromSlash(name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return attachmentContent(name, data)
+	}
+
+	return nil, errFileNotFound
+}
+
+func readRootFile(dir, name string) ([]byte, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("opening root: %w", err)
+	}
+	defer root.Close()
+
+	f, err := root.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxAttachmentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+
+	if len(data) > maxAttachmentSize {
+		return nil, fmt.Errorf("%w: over %d bytes", errFileTooLarge, maxAttachmentSize)
+	}
+
+	return data, nil
+}
+
+// attachmentContent converts file data to sampling content based on its extension.
+func attachmentContent(name string, data []byte) (mcp.Content, error) {
+	mimeType, _, _ := strings.Cut(mime.TypeByExtension(filepath.Ext(name)), ";")
+
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return &mcp.ImageContent{Data: data, MIMEType: mimeType}, nil
+	case strings.HasPrefix(mimeType, "audio/"):
+		return &mcp.AudioContent{Data: data, MIMEType: mimeType}, nil
+	case !utf8.Valid(data):
+		return nil, errBinaryFile
+	default:
+		return &mcp.TextContent{Text: "## File: " + name + "\n\n```\n" + string(data) + "\n```"}, nil
+	}
+}
@@ -0,0 +1,222 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/monke/grimoire/internal/grimoire"
+)
+
+// maxCompletionValues is the maximum number of values a completion result may contain.
+const maxCompletionValues = 100
+
+const (
+	refPrompt   = "ref/prompt"
+	refResource = "ref/resource"
+)
+
+func (s *Server) handleComplete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	ref := req.Params.Ref
+	arg := req.Params.Argument
+
+	slog.DebugContext(ctx, "completion requested",
+		slog.String("ref_type", ref.Type),
+		slog.String("ref_name", ref.Name),
+		slog.String("ref_uri", ref.URI),
+		slog.String("argument", arg.Name))
+
+	var values []string
+
+	switch ref.Type {
+	case refPrompt:
+		values = s.completePromptArgument(ctx, req.Session, ref.Name, arg)
+	case refResource:
+		values = s.completeResourceArgument(ref.URI, arg)
+	}
+
+	return completionResult(values), nil
+}
+
+// completePromptArgument completes an argument of a skill prompt with its
+// allowed values and the completion sources declared in the skill's frontmatter.
+func (s *Server) completePromptArgument(
+	ctx context.Context,
+	session *mcp.ServerSession,
+	name string,
+	arg mcp.CompleteParamsArgument,
+) []string {
+	skill, err := s.store.Get(grimoire.TypeSkill, name)
+	if err != nil {
+		return nil
+	}
+
+	declared := skill.Argument(arg.Name)
+	if declared == nil {
+		return nil
+	}
+
+	values := filterPrefix(declared.Choices(), arg.Value)
+
+	complete := declared.Complete
+	if complete == nil {
+		return values
+	}
+
+	values = append(values, filterPrefix(complete.Values, arg.Value)...)
+
+	if complete.Entries != "" {
+		values = append(values, s.completeEntryNames(complete.Entries, arg.Value)...)
+	}
+
+	if complete.Paths {
+		values = append(values, completePaths(ctx, session, arg.Value)...)
+	}
+
+	return values
+}
+
+// completeResourceArgument completes the {name} variable of the resource templates.
+func (s *Server) completeResourceArgument(uri string, arg mcp.CompleteParamsArgument) []string {
+	if arg.Name != "name" {
+		return nil
+	}
+
+	typ, ok := resourceTemplateTypes[uri]
+	if !ok {
+		return nil
+	}
+
+	return s.completeEntryNames(typ, arg.Value)
+}
+
+func (s *Server) completeEntryNames(typ grimoire.Type, prefix string) []string {
+	entries := s.store.ListCurrent(typ)
+
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name
+	}
+
+	return filterPrefix(names, prefix)
+}
+
+// completePaths completes file paths relative to the client's roots.
+// Returns nothing if the client does not support roots.
+func completePaths(ctx context.Context, session *mcp.ServerSession, prefix string) []string {
+	roots, err := rootDirs(ctx, session)
+	if errors.Is(err, errRootsUnsupported) {
+		slog.DebugContext(ctx, "client does not support roots")
+
+		return nil
+	}
+
+	if err != nil {
+		slog.WarnContext(ctx, "failed to list roots", slog.Any("error", err))
+
+		return nil
+	}
+
+	var values []string
+
+	for _, dir := range roots {
+		values = append(values, listPathCompletions(dir, prefix)...)
+	}
+
+	return values
+}
+
+// rootPath converts a file:// root URI into a local directory path.
+func rootPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+
+	return filepath.FromSlash(u.Path), true
+}
+
+// listPathCompletions lists entries of the directory named by prefix (relative to root)
+// whose names start with the last element of prefix. Directories get a trailing slash.
+// Hidden files are only offered when the prefix explicitly starts with a dot.
+func listPathCompletions(root, prefix string) []string {
+	dir, base := path.Split(prefix)
+
+	if dir != "" && !filepath.IsLocal(filepath.FromSlash(dir)) {
+		return nil
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(dir)))
+	if err != nil {
+		return nil
+	}
+
+	var values []string
+
+	for _, d := range entries {
+		name := d.Name()
+
+		if !strings.HasPrefix(name, base) {
+			continue
+		}
+
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+
+		value := dir + name
+		if d.IsDir() {
+			value += "/"
+		}
+
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// filterPrefix returns the values starting with prefix (case-insensitive).
+func filterPrefix(values []string, prefix string) []string {
+	prefix = strings.ToLower(prefix)
+
+	var result []string
+
+	for _, v := range values {
+		if strings.HasPrefix(strings.ToLower(v), prefix) {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// completionResult deduplicates values and truncates them to the protocol limit.
+func completionResult(values []string) *mcp.CompleteResult {
+	unique := make([]string, 0, len(values))
+
+	for _, v := range values {
+		if !slices.Contains(unique, v) {
+			unique = append(unique, v)
+		}
+	}
+
+	details := mcp.CompletionResultDetails{
+		Values: unique,
+		Total:  len(unique),
+	}
+
+	if len(unique) > maxCompletionValues {
+		details.Values = unique[:maxCompletionValues]
+		details.HasMore = true
+	}
+
+	return &mcp.CompleteResult{Completion: details}
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/monke/grimoire/internal/grimoire"
+)
+
+const elicitActionAccept = "accept"
+
+// resolveArguments returns the validated argument values for entry, asking the
+// user for missing required arguments via elicitation when the client supports it.
+// Returns an error wrapping grimoire.ErrMissingArguments if any remain missing,
+// or grimoire.ErrInvalidArgumentValue if a value does not match its declaration.
+func (s *Server) resolveArguments(
+	ctx context.Context,
+	session *mcp.ServerSession,
+	entry *grimoire.Entry,
+	values map[string]string,
+) (map[string]string, error) {
+	missing := entry.MissingArguments(values)
+
+	if len(missing) > 0 && supportsElicitation(session) {
+		elicited, err := elicitArguments(ctx, session, entry, missing)
+		if err != nil {
+			slog.WarnContext(ctx, "argument elicitation failed",
+				slog.String("name", entry.Name), slog.Any("error", err))
+		}
+
+		resolved := make(map[string]string, len(values)+len(elicited))
+		maps.Copy(resolved, values)
+		maps.Copy(resolved, elicited)
+
+		values = resolved
+		missing = entry.MissingArguments(values)
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%s %q: %w: %s",
+			entry.Type, entry.Name, grimoire.ErrMissingArguments, strings.Join(missing, ", "))
+	}
+
+	err := entry.ValidateArguments(values)
+	if err != nil {
+		return nil, fmt.Errorf("validating arguments: %w", err)
+	}
+
+	return values, nil
+}
+
+func supportsElicitation(session *mcp.ServerSession) bool {
+	if session == nil {
+		return false
+	}
+
+	params := session.InitializeParams()
+
+	return params != nil && params.Capabilities != nil && params.Capabilities.Elicitation != nil
+}
+
+// elicitArguments asks the user for the named arguments of entry.
+// Returns no values if the user declines or cancels.
+func elicitArguments(
+	ctx context.Context,
+	session *mcp.ServerSession,
+	entry *grimoire.Entry,
+	names []string,
+) (map[string]string, error) {
+	slog.DebugContext(ctx, "eliciting arguments", slog.String("name", entry.Name), slog.Any("arguments", names))
+
+	result, err := session.Elicit(ctx, &mcp.ElicitParams{
+		Message:         fmt.Sprintf("%s %q requires: %s", entry.Type, entry.Name, strings.Join(names, ", ")),
+		RequestedSchema: argumentsSchema(entry, names),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("elicit: %w", err)
+	}
+
+	if result.Action != elicitActionAccept {
+		slog.DebugContext(ctx, "argument elicitation not accepted", slog.String("action", result.Action))
+
+		return map[string]string{}, nil
+	}
+
+	values := make(map[string]string, len(result.Content))
+	for name, v := range result.Content {
+		values[name] = fmt.Sprint(v)
+	}
+
+	return values, nil
+}
+
+// argumentsSchema builds an elicitation schema for the named arguments of entry.
+func argumentsSchema(entry *grimoire.Entry, names []string) *jsonschema.Schema {
+	schema := &jsonschema.Schema{
+		Type:       "object",
+		Properties: make(map[string]*jsonschema.Schema, len(names)),
+		Required:   names,
+	}
+
+	for _, name := range names {
+		prop := &jsonschema.Schema{Type: "string", Title: name}
+
+		if arg := entry.Argument(name); arg != nil {
+			prop = arg.JSONSchema()
+		}
+
+		schema.Properties[name] = prop
+	}
+
+	return schema
+}
@@ -0,0 +1,392 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/monke/grimoire/internal/grimoire"
+)
+
+var (
+	errUnexpectedContentType = errors.New("unexpected content type in sampling result")
+	errAgentTimeout          = errors.New("agent timed out")
+	errSamplingUnavailable   = errors.New("client does not support sampling")
+)
+
+// retryDelay is the pause before retrying a transient agent failure.
+const retryDelay = time.Second
+
+// agentResponse is the outcome of a successful agent execution.
+// Data holds the decoded JSON for agents with an output schema;
+// Media holds image or audio output.
+type agentResponse struct {
+	Text  string
+	Data  any
+	Media mcp.Content
+
+	// Transcript is the conversation that produced the response.
+	Transcript []*mcp.SamplingMessage
+}
+
+// executor runs an agent's messages on a model and returns the reply.
+type executor interface {
+	execute(ctx context.Context, agent *grimoire.Entry, messages []*mcp.SamplingMessage) (mcp.Content, error)
+}
+
+// samplingExecutor runs agents through MCP sampling on the client.
+type samplingExecutor struct {
+	session *mcp.ServerSession
+}
+
+// agentRun holds the state shared by all agents executed in one tool call.
+type agentRun struct {
+	// session is the session of the tool call, used to elicit arguments.
+	session *mcp.ServerSession
+
+	// sampling is nil if the client does not support sampling.
+	sampling    executor
+	progress    *progressReporter
+	slots       chan struct{}
+	arguments   map[string]map[string]string
+	attachments []*mcp.SamplingMessage
+}
+
+// newAgentRun prepares the shared state for executing a plan in a tool call.
+// Progress is reported twice (start and finish) for every agent that samples.
+func (s *Server) newAgentRun(req *mcp.CallToolRequest, plan *agentPlan) *agentRun {
+	steps := len(s.samplingAgents(plan.agents))
+
+	run := &agentRun{
+		session:     req.Session,
+		progress:    newProgressReporter(req, 2*steps),
+		slots:       make(chan struct{}, s.cfg.Execution.ConcurrencyLimit()),
+		arguments:   plan.arguments,
+		attachments: plan.attachments,
+	}
+
+	if supportsSampling(req.Session) {
+		run.sampling = &samplingExecutor{session: req.Session}
+	}
+
+	return run
+}
+
+// executorFor selects the backend for an agent: the local executor if the agent
+// pins it, otherwise sampling, falling back to the local executor if the client
+// cannot sample.
+func (s *Server) executorFor(run *agentRun, agent *grimoire.Entry) (executor, error) {
+	switch {
+	case agent.Executor == grimoire.ExecutorLocal && s.local != nil:
+		return s.local, nil
+	case agent.Executor == grimoire.ExecutorLocal:
+		return nil, fmt.Errorf("%w: execution.local is not configured", grimoire.ErrInvalidExecutor)
+	case run.sampling != nil:
+		return run.sampling, nil
+	case agent.Executor != grimoire.ExecutorSampling && s.local != nil:
+		return s.local, nil
+	default:
+		return nil, errSamplingUnavailable
+	}
+}
+
+// runAgents executes agents concurrently, bounded by the run's concurrency slots.
+// Results are returned in the order of agents. When ctx is cancelled, running agents
+// are cancelled and agents still waiting for a slot are not started.
+// Pipelines do not occupy a slot themselves; their steps do.
+func (s *Server) runAgents(
+	ctx context.Context,
+	run *agentRun,
+	agents []*grimoire.Entry,
+	userContext string,
+) []agentResult {
+	results := make([]agentResult, len(agents))
+
+	var wg sync.WaitGroup
+
+	for i, agent := range agents {
+		wg.Go(func() {
+			if agent.IsPipeline() {
+				results[i] = s.runPipeline(ctx, run, agent, userContext)
+			} else {
+				results[i] = s.runAgent(ctx, run, agent, userContext)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// runAgent waits for a free slot and executes a single agent,
+// reporting progress when it starts and finishes.
+func (s *Server) runAgent(
+	ctx context.Context,
+	run *agentRun,
+	agent *grimoire.Entry,
+	userContext string,
+) agentResult {
+	progress := run.progress
+
+	select {
+	case run.slots <- struct{}{}:
+		defer func() { <-run.slots }()
+	case <-ctx.Done():
+		return agentResult{Name: agent.Name, Error: fmt.Errorf("not started: %w", ctx.Err())}
+	}
+
+	slog.DebugContext(ctx, "executing agent", slog.String("name", agent.Name))
+	progress.step(ctx, agent.Name+" started")
+
+	start := time.Now()
+
+	var (
+		response agentResponse
+		attempts int
+	)
+
+	exec, prompt, err := s.prepareAgent(run, agent, userContext)
+	if err == nil {
+		response, attempts, err = s.executeWithRetry(ctx, run, exec, agent, s.agentMessages(run, prompt))
+	}
+
+	duration := time.Since(start)
+
+	result := agentResult{
+		Name:       agent.Name,
+		Output:     response.Text,
+		Data:       response.Data,
+		Media:      response.Media,
+		Error:      err,
+		Duration:   duration,
+		Attempts:   attempts,
+		TimedOut:   errors.Is(err, errAgentTimeout),
+		Transcript: response.Transcript,
+	}
+
+	switch {
+	case result.TimedOut:
+		slog.WarnContext(ctx, "agent timed out",
+			slog.String("name", agent.Name), slog.Duration("duration", duration))
+		progress.step(ctx, agent.Name+" timed out")
+	case err != nil:
+		slog.WarnContext(ctx, "agent execution failed",
+			slog.String("name", agent.Name), slog.Duration("duration", duration), slog.Any("error", err))
+		progress.step(ctx, agent.Name+" failed")
+	default:
+		slog.DebugContext(ctx, "agent execution completed",
+			slog.String("name", agent.Name), slog.Duration("duration", duration))
+		progress.step(ctx, agent.Name+" finished")
+	}
+
+	return result
+}
+
+// prepareAgent selects the executor for an agent and builds its prompt from
+// the rendered body, the user context and any instructions the agent needs.
+func (s *Server) prepareAgent(run *agentRun, agent *grimoire.Entry, userContext string) (executor, string, error) {
+	exec, err := s.executorFor(run, agent)
+	if err != nil {
+		return nil, "", err
+	}
+
+	body, err := agent.RenderBody(run.arguments[agent.Name])
+	if err != nil {
+		return nil, "", fmt.Errorf("prompt: %w", err)
+	}
+
+	prompt := agentPrompt(body, userContext)
+
+	return exec, outputInstructions(agent, s.turnInstructions(agent, prompt)), nil
+}
+
+// executeWithRetry executes an agent, retrying transient failures up to the
+// configured retry limit. Returns the response and the number of attempts made.
+func (s *Server) executeWithRetry(
+	ctx context.Context,
+	run *agentRun,
+	exec executor,
+	agent *grimoire.Entry,
+	messages []*mcp.SamplingMessage,
+) (agentResponse, int, error) {
+	retries := s.cfg.Execution.RetryLimit()
+
+	for attempt := 1; ; attempt++ {
+		response, err := s.executeAttempt(ctx, run, exec, agent, messages)
+		if err == nil || attempt > retries || !isTransient(err) {
+			return response, attempt, err
+		}
+
+		slog.WarnContext(ctx, "retrying agent after transient failure",
+			slog.String("name", agent.Name), slog.Int("attempt", attempt), slog.Any("error", err))
+
+		select {
+		case <-time.After(retryDelay):
+		case <-ctx.Done():
+			return agentResponse{}, attempt, fmt.Errorf("retry cancelled: %w", ctx.Err())
+		}
+	}
+}
+
+// executeAttempt executes an agent once, bounded by the agent's timeout.
+// This includes all turns of multi-turn agents and, for agents with an output
+// schema, one correction of invalid output.
+func (s *Server) executeAttempt(
+	ctx context.Context,
+	run *agentRun,
+	exec executor,
+	agent *grimoire.Entry,
+	messages []*mcp.SamplingMessage,
+) (agentResponse, error) {
+	timeout := s.cfg.Execution.TimeoutFor(agent)
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var response agentResponse
+
+	content, err := exec.execute(attemptCtx, agent, messages)
+	if err == nil && agent.MaxTurns > 1 {
+		content, messages, err = s.converse(attemptCtx, run, exec, agent, messages, content)
+	}
+
+	if err == nil {
+		response, err = s.toResponse(attemptCtx, exec, agent, messages, content)
+	}
+
+	if err == nil && response.Transcript == nil {
+		response.Transcript = append(messages[:len(messages):len(messages)],
+			&mcp.SamplingMessage{Role: "assistant", Content: content})
+	}
+
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return agentResponse{}, fmt.Errorf("%w after %s", errAgentTimeout, timeout)
+	}
+
+	return response, err
+}
+
+// agentMessages builds the messages sent to an agent: the run's attachments
+// followed by the prompt.
+func (s *Server) agentMessages(run *agentRun, prompt string) []*mcp.SamplingMessage {
+	messages := make([]*mcp.SamplingMessage, 0, len(run.attachments)+1)
+	messages = append(messages, run.attachments...)
+
+	return append(messages, &mcp.SamplingMessage{
+		Role:    "user",
+		Content: &mcp.TextContent{Text: prompt},
+	})
+}
+
+// toResponse converts an executor's reply into an agent response. Text replies
+// of agents with an output schema are parsed as structured output.
+func (s *Server) toResponse(
+	ctx context.Context,
+	exec executor,
+	agent *grimoire.Entry,
+	messages []*mcp.SamplingMessage,
+	content mcp.Content,
+) (agentResponse, error) {
+	switch c := content.(type) {
+	case *mcp.TextContent:
+		if agent.ResolvedOutputSchema() != nil {
+			return s.structuredResponse(ctx, exec, agent, messages, c.Text)
+		}
+
+		return agentResponse{Text: c.Text}, nil
+	case *mcp.ImageContent, *mcp.AudioContent:
+		if agent.ResolvedOutputSchema() != nil {
+			return agentResponse{}, fmt.Errorf("%w: expected JSON text, got %s", errInvalidOutput, mediaType(c))
+		}
+
+		return agentResponse{Media: c}, nil
+	default:
+		return agentResponse{}, errUnexpectedContentType
+	}
+}
+
+// isTransient reports whether a failed agent attempt is worth retrying: transport
+// timeouts, rate limits and server errors from the local executor, and internal
+// errors from the client. Other errors, such as a rejected sampling request, are not.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, errAgentTimeout) {
+		return false
+	}
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusTooManyRequests || statusErr.code >= http.StatusInternalServerError
+	}
+
+	var wireErr *jsonrpc.Error
+	if errors.As(err, &wireErr) {
+		return wireErr.Code == jsonrpc.CodeInternalError
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (e *samplingExecutor) execute(
+	ctx context.Context,
+	agent *grimoire.Entry,
+	messages []*mcp.SamplingMessage,
+) (mcp.Content, error) {
+	result, err := e.session.CreateMessage(ctx, samplingParams(agent, messages))
+	if err != nil {
+		return nil, fmt.Errorf("sampling failed: %w", err)
+	}
+
+	return result.Content, nil
+}
+
+// samplingParams builds the sampling request for an agent, applying the
+// sampling parameters from its frontmatter.
+func samplingParams(agent *grimoire.Entry, messages []*mcp.SamplingMessage) *mcp.CreateMessageParams {
+	params := &mcp.CreateMessageParams{
+		Messages:     messages,
+		SystemPrompt: agent.Description,
+		MaxTokens:    agent.Sampling.TokenLimit(),
+	}
+
+	sampling := agent.Sampling
+	if sampling == nil {
+		return params
+	}
+
+	// The SDK omits a temperature of 0, so the client's default applies then;
+	// the store warns about such agents when loading.
+	if sampling.Temperature != nil {
+		params.Temperature = *sampling.Temperature
+	}
+
+	params.StopSequences = sampling.StopSequences
+
+	if len(sampling.Models) > 0 || sampling.CostPriority > 0 ||
+		sampling.SpeedPriority > 0 || sampling.IntelligencePriority > 0 {
+		prefs := &mcp.ModelPreferences{
+			CostPriority:         sampling.CostPriority,
+			SpeedPriority:        sampling.SpeedPriority,
+			IntelligencePriority: sampling.IntelligencePriority,
+		}
+
+		for _, model := range sampling.Models {
+			prefs.Hints = append(prefs.Hints, &mcp.ModelHint{Name: model})
+		}
+
+		params.ModelPreferences = prefs
+	}
+
+	return params
+}
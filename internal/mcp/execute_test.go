@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+func TestIsTransient(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"canceled", context.Canceled, false},
+		{"deadline exceeded", fmt.Errorf("sampling failed: %w", context.DeadlineExceeded), false},
+		{"agent timeout", fmt.Errorf("%w after 1m0s", errAgentTimeout), false},
+		{"too many requests", &statusError{code: http.StatusTooManyRequests}, true},
+		{"server error", fmt.Errorf("wrapped: %w", &statusError{code: http.StatusBadGateway}), true},
+		{"bad request", &statusError{code: http.StatusBadRequest}, false},
+		{"unauthorized", &statusError{code: http.StatusUnauthorized}, false},
+		{"internal JSON-RPC error", &jsonrpc.Error{Code: jsonrpc.CodeInternalError}, true},
+		{"method not found", &jsonrpc.Error{Code: jsonrpc.CodeMethodNotFound}, false},
+		{"invalid params", fmt.Errorf("sampling failed: %w", &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams}), false},
+		{"network timeout", &net.DNSError{Err: "timeout", IsTimeout: true}, true},
+		{"network error", &net.DNSError{Err: "no such host", IsNotFound: true}, false},
+		{"other", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := isTransient(tt.err)
+			if got != tt.want {
+				t.Errorf("isTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
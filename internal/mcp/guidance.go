@@ -15,34 +15,145 @@ import (
 type guidanceInput struct {
 	Name  string   `json:"name,omitempty"  jsonschema:"Name of the guidance to load"`
 	Names []string `json:"names,omitempty" jsonschema:"Multiple names to load in batch"`
+
+	Arguments map[string]string `json:"arguments,omitempty" jsonschema:"Argument values for skill templating"`
+}
+
+// guidanceOutput is the structured output of the guidance tool.
+type guidanceOutput struct {
+	Entries  []guidanceEntry   `json:"entries,omitempty"   jsonschema:"Loaded entries in request order"`
+	NotFound []string          `json:"not_found,omitempty" jsonschema:"Requested names that were not found"`
+	Failed   []guidanceFailure `json:"failed,omitempty"    jsonschema:"Entries that were found but failed to load"`
+}
+
+// guidanceFailure is an entry that was found but could not be rendered,
+// e.g. because a required argument is missing.
+type guidanceFailure struct {
+	Name  string `json:"name"  jsonschema:"Entry name"`
+	Error string `json:"error" jsonschema:"Why the entry failed to load"`
+}
+
+// guidanceEntry is a loaded entry with its rendered body.
+type guidanceEntry struct {
+	Name        string `json:"name"                  jsonschema:"Entry name"`
+	Type        string `json:"type"                  jsonschema:"Entry type (rule, skill, agent)"`
+	Description string `json:"description,omitempty" jsonschema:"Entry description"`
+	Body        string `json:"body"                  jsonschema:"Rendered entry content"`
 }
 
 func (s *Server) registerGuidance() {
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "guidance",
+		Title:       "Load Guidance",
+		Annotations: readOnlyAnnotations("Load Guidance"),
+		Icons:       toolIcons("guidance"),
 		Description: grimoire.BuildGuidanceDescription(s.store),
 	}, s.handleGuidance)
 }
 
 func (s *Server) handleGuidance(
 	ctx context.Context,
-	_ *mcp.CallToolRequest,
+	req *mcp.CallToolRequest,
 	input guidanceInput,
-) (*mcp.CallToolResult, any, error) {
+) (*mcp.CallToolResult, *guidanceOutput, error) {
 	if input.Name != "" {
-		return s.handleGuidanceByName(ctx, []string{input.Name})
+		return s.handleGuidanceByName(ctx, req.Session, []string{input.Name}, input.Arguments)
 	}
 
 	if len(input.Names) > 0 {
-		return s.handleGuidanceByName(ctx, input.Names)
+		return s.handleGuidanceByName(ctx, req.Session, input.Names, input.Arguments)
 	}
 
 	return errorResultMsg("provide name or names parameter"), nil, nil
 }
 
-func (s *Server) handleGuidanceByName(ctx context.Context, names []string) (*mcp.CallToolResult, any, error) {
+func (s *Server) handleGuidanceByName(
+	ctx context.Context,
+	session *mcp.ServerSession,
+	names []string,
+	arguments map[string]string,
+) (*mcp.CallToolResult, *guidanceOutput, error) {
 	slog.DebugContext(ctx, "loading guidance by name", slog.Any("names", names))
 
+	entries, notFound := s.findGuidance(names)
+
+	if len(entries) == 0 {
+		slog.WarnContext(ctx, "guidance not found", slog.Any("names", notFound))
+
+		return errorResultMsg(fmt.Sprintf("guidance not found: %v", notFound)), nil, nil
+	}
+
+	var (
+		loaded   []*grimoire.Entry
+		bodies   []string
+		failures []guidanceFailure
+	)
+
+	for _, entry := range entries {
+		body, err := s.renderGuidance(ctx, session, entry, arguments)
+		if err != nil {
+			failures = append(failures, guidanceFailure{Name: entry.Name, Error: err.Error()})
+
+			continue
+		}
+
+		loaded = append(loaded, entry)
+		bodies = append(bodies, body)
+	}
+
+	slog.DebugContext(ctx, "guidance loaded",
+		slog.Int("count", len(loaded)), slog.Any("not_found", notFound), slog.Int("failed", len(failures)))
+
+	if len(loaded) == 0 {
+		return errorResultMsg(formatFailures(failures)), nil, nil
+	}
+
+	result, output := guidanceResult(loaded, bodies, notFound, failures)
+
+	return result, output, nil
+}
+
+// guidanceResult formats loaded entries, followed by the names that were not
+// found and the entries that failed to load.
+func guidanceResult(
+	loaded []*grimoire.Entry,
+	bodies, notFound []string,
+	failures []guidanceFailure,
+) (*mcp.CallToolResult, *guidanceOutput) {
+	result := formatEntries(loaded, bodies)
+	if len(notFound) > 0 {
+		result += fmt.Sprintf("\n\n---\nNot found: %v", notFound)
+	}
+
+	if len(failures) > 0 {
+		result += "\n\n---\n" + formatFailures(failures)
+	}
+
+	output := &guidanceOutput{
+		Entries:  make([]guidanceEntry, len(loaded)),
+		NotFound: notFound,
+		Failed:   failures,
+	}
+
+	for i, entry := range loaded {
+		output.Entries[i] = guidanceEntry{
+			Name:        entry.Name,
+			Type:        string(entry.Type),
+			Description: entry.Description,
+			Body:        bodies[i],
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, output
+}
+
+// findGuidance looks up entries by name, trying skills, rules and agents in turn.
+// Returns the entries found in request order and the names that were not.
+func (s *Server) findGuidance(names []string) ([]*grimoire.Entry, []string) {
 	var (
 		entries  []*grimoire.Entry
 		notFound []string
@@ -66,27 +177,58 @@ func (s *Server) handleGuidanceByName(ctx context.Context, names []string) (*mcp
 		}
 	}
 
-	if len(entries) == 0 {
-		slog.WarnContext(ctx, "guidance not found", slog.Any("names", notFound))
+	return entries, notFound
+}
 
-		return errorResultMsg(fmt.Sprintf("guidance not found: %v", notFound)), nil, nil
+// renderGuidance returns the rendered body of a loaded entry. Skill arguments
+// are resolved first, and skills list the agents they delegate to.
+func (s *Server) renderGuidance(
+	ctx context.Context,
+	session *mcp.ServerSession,
+	entry *grimoire.Entry,
+	arguments map[string]string,
+) (string, error) {
+	warnDeprecated(ctx, entry)
+
+	values := arguments
+
+	if entry.Type == grimoire.TypeSkill {
+		var err error
+
+		values, err = s.resolveArguments(ctx, session, entry, arguments)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to resolve guidance arguments",
+				slog.String("name", entry.Name), slog.Any("error", err))
+
+			return "", err
+		}
 	}
 
-	slog.DebugContext(ctx, "guidance loaded", slog.Int("count", len(entries)), slog.Any("not_found", notFound))
+	body, err := entry.RenderBody(values)
+	if err != nil {
+		slog.WarnContext(ctx, "guidance render failed", slog.String("name", entry.Name), slog.Any("error", err))
 
-	result := formatEntries(entries)
-	if len(notFound) > 0 {
-		result += fmt.Sprintf("\n\n---\nNot found: %v", notFound)
+		return "", fmt.Errorf("rendering %q: %w", entry.Name, err)
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: result},
-		},
-	}, nil, nil
+	return entry.DeprecationNotice() + body + grimoire.BuildDelegationSection(s.store, entry), nil
+}
+
+// formatFailures lists entries that failed to load, one per line.
+func formatFailures(failures []guidanceFailure) string {
+	var b strings.Builder
+
+	b.WriteString("Failed to load:")
+
+	for _, f := range failures {
+		fmt.Fprintf(&b, "\n- %s: %s", f.Name, f.Error)
+	}
+
+	return b.String()
 }
 
-func formatEntries(entries []*grimoire.Entry) string {
+// formatEntries renders entries with their bodies, one section per entry.
+func formatEntries(entries []*grimoire.Entry, bodies []string) string {
 	var b strings.Builder
 
 	for i, entry := range entries {
@@ -95,7 +237,7 @@ func formatEntries(entries []*grimoire.Entry) string {
 		}
 
 		fmt.Fprintf(&b, "# %s\n\n", entry.Name)
-		b.WriteString(entry.Body)
+		b.WriteString(bodies[i])
 	}
 
 	return b.String()
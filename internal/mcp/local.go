@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/monke/grimoire/internal/grimoire"
+)
+
+// maxErrorBody limits how much of an error response is included in the error message.
+const maxErrorBody = 1024
+
+var errNoChoices = errors.New("local executor returned no choices")
+
+// localExecutor runs agents on an OpenAI-compatible chat completions endpoint.
+type localExecutor struct {
+	client *http.Client
+	url    string
+	model  string
+	apiKey string
+}
+
+// chatMessage is a message in the chat completions API.
+// Content is a string, or a list of chatParts for images.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+// chatPart is one part of a multimodal message.
+type chatPart struct {
+	Type     string    `json:"type"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	MaxTokens   int64         `json:"max_tokens,omitempty"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	Stop        []string      `json:"stop,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+// statusError is a non-success HTTP response from the local executor.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("local executor: HTTP %d: %s", e.code, e.body)
+}
+
+// newLocalExecutor creates an executor for the configured endpoint.
+// The API key is read from the environment once, at startup.
+func newLocalExecutor(cfg *grimoire.LocalConfig) *localExecutor {
+	e := &localExecutor{
+		client: &http.Client{},
+		url:    strings.TrimSuffix(cfg.URL, "/") + "/chat/completions",
+		model:  cfg.Model,
+	}
+
+	if cfg.APIKeyEnv != "" {
+		e.apiKey = os.Getenv(cfg.APIKeyEnv)
+		if e.apiKey == "" {
+			slog.Warn("local executor API key is not set", slog.String("env", cfg.APIKeyEnv))
+		}
+	}
+
+	return e
+}
+
+func (e *localExecutor) execute(
+	ctx context.Context,
+	agent *grimoire.Entry,
+	messages []*mcp.SamplingMessage,
+) (mcp.Content, error) {
+	request, err := e.chatRequest(agent, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("local executor: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+		return nil, &statusError{code: resp.StatusCode, body: strings.TrimSpace(string(text))}
+	}
+
+	var result chatResponse
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, fmt.Errorf("local executor: decoding response: %w", err)
+	}
+
+	if len(result.Choices) == 0 {
+		return nil, errNoChoices
+	}
+
+	return &mcp.TextContent{Text: result.Choices[0].Message.Content}, nil
+}
+
+// chatRequest converts sampling messages and the agent's sampling parameters
+// into a chat completions request. The agent description becomes the system message.
+// Images are sent as data URLs; audio is not supported.
+func (e *localExecutor) chatRequest(agent *grimoire.Entry, messages []*mcp.SamplingMessage) (*chatRequest, error) {
+	request := &chatRequest{
+		Model:     e.model,
+		Messages:  []chatMessage{{Role: "system", Content: agent.Description}},
+		MaxTokens: agent.Sampling.TokenLimit(),
+	}
+
+	if agent.Sampling != nil {
+		request.Temperature = agent.Sampling.Temperature
+		request.Stop = agent.Sampling.StopSequences
+	}
+
+	for _, msg := range messages {
+		message := chatMessage{Role: string(msg.Role)}
+
+		switch c := msg.Content.(type) {
+		case *mcp.TextContent:
+			message.Content = c.Text
+		case *mcp.ImageContent:
+			message.Content = []chatPart{{
+				Type:     "image_url",
+				ImageURL: &imageURL{URL: "data:" + c.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(c.Data)},
+			}}
+		default:
+			return nil, errUnexpectedContentType
+		}
+
+		request.Messages = append(request.Messages, message)
+	}
+
+	return request, nil
+}
@@ -0,0 +1,211 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	loggerName = "grimoire"
+
+	// methodSetLevel is the request a client sends to set its logging level.
+	methodSetLevel = "logging/setLevel"
+)
+
+// logHandler is a slog.Handler that passes records to a wrapped handler and
+// forwards them to every connected session as MCP log notifications.
+// Sessions only receive records at or above the level set via logging/setLevel.
+type logHandler struct {
+	next   slog.Handler
+	server *mcp.Server
+	levels *logLevels
+	attrs  []slog.Attr
+	groups []string
+}
+
+// logLevels tracks the logging level each session set via logging/setLevel,
+// so records that no destination accepts are skipped early.
+type logLevels struct {
+	mu     sync.Mutex
+	levels map[*mcp.ServerSession]slog.Level
+}
+
+// LogHandler returns a slog.Handler that writes to next and also forwards
+// records to connected clients.
+func (s *Server) LogHandler(next slog.Handler) slog.Handler {
+	return &logHandler{
+		next:   next,
+		server: s.mcp,
+		levels: s.logLevels,
+	}
+}
+
+// Enabled reports whether the wrapped handler or a connected session that set
+// a logging level accepts records at level.
+func (h *logHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level) || h.levels.enabled(h.server, level)
+}
+
+func (h *logHandler) Handle(ctx context.Context, r slog.Record) error {
+	// Errors from sending notifications are ignored: logging them would recurse.
+	for session := range h.server.Sessions() {
+		_ = session.Log(ctx, &mcp.LoggingMessageParams{
+			Level:  loggingLevel(r.Level),
+			Logger: loggerName,
+			Data:   h.data(r),
+		})
+	}
+
+	if !h.next.Enabled(ctx, r.Level) {
+		return nil
+	}
+
+	//nolint:wrapcheck // pass through the wrapped handler's error unchanged
+	return h.next.Handle(ctx, r)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithAttrs(attrs)
+	clone.attrs = append(clone.attrs[:len(clone.attrs):len(clone.attrs)], h.qualify(attrs)...)
+
+	return &clone
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	clone := *h
+	clone.next = h.next.WithGroup(name)
+	clone.groups = append(clone.groups[:len(clone.groups):len(clone.groups)], name)
+
+	return &clone
+}
+
+// data builds the notification payload: the message plus all attributes,
+// with group names joined into dotted keys.
+func (h *logHandler) data(r slog.Record) map[string]any {
+	data := make(map[string]any, len(h.attrs)+r.NumAttrs()+1)
+	data["message"] = r.Message
+
+	for _, a := range h.attrs {
+		data[a.Key] = a.Value.Resolve().Any()
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		for _, q := range h.qualify([]slog.Attr{a}) {
+			data[q.Key] = q.Value.Resolve().Any()
+		}
+
+		return true
+	})
+
+	for k, v := range data {
+		if err, ok := v.(error); ok {
+			data[k] = err.Error()
+		}
+	}
+
+	return data
+}
+
+// qualify prefixes attribute keys with the handler's groups.
+func (h *logHandler) qualify(attrs []slog.Attr) []slog.Attr {
+	if len(h.groups) == 0 {
+		return attrs
+	}
+
+	prefix := strings.Join(h.groups, ".") + "."
+
+	result := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		result[i] = slog.Attr{Key: prefix + a.Key, Value: a.Value}
+	}
+
+	return result
+}
+
+// middleware records the level of successful logging/setLevel requests.
+func (l *logLevels) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		result, err := next(ctx, method, req)
+		if err != nil || method != methodSetLevel {
+			//nolint:wrapcheck // pass through the wrapped handler's error unchanged
+			return result, err
+		}
+
+		session, isSession := req.GetSession().(*mcp.ServerSession)
+		params, isSetLevel := req.GetParams().(*mcp.SetLoggingLevelParams)
+
+		if isSession && isSetLevel {
+			l.mu.Lock()
+			l.levels[session] = slogLevel(params.Level)
+			l.mu.Unlock()
+		}
+
+		return result, nil
+	}
+}
+
+// enabled reports whether a session connected to server accepts records at level.
+// Levels of sessions that are no longer connected are dropped.
+func (l *logLevels) enabled(server *mcp.Server, level slog.Level) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.levels) == 0 {
+		return false
+	}
+
+	connected := make(map[*mcp.ServerSession]bool, len(l.levels))
+	for session := range server.Sessions() {
+		connected[session] = true
+	}
+
+	accepted := false
+
+	for session, minLevel := range l.levels {
+		switch {
+		case !connected[session]:
+			delete(l.levels, session)
+		case level >= minLevel:
+			accepted = true
+		}
+	}
+
+	return accepted
+}
+
+// slogLevel maps an MCP logging level to the slog level of the records it accepts.
+func slogLevel(level mcp.LoggingLevel) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "info", "notice":
+		return slog.LevelInfo
+	case "warning":
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// loggingLevel maps a slog level to the closest MCP logging level.
+func loggingLevel(level slog.Level) mcp.LoggingLevel {
+	switch {
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelWarn:
+		return "warning"
+	case level >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
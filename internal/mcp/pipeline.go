@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/monke/grimoire/internal/grimoire"
+)
+
+// runPipeline executes the steps of a pipeline agent in order. Agents within a
+// step run in parallel; the outputs of a step become context for the next one.
+// The pipeline stops at the first step with a failed agent.
+func (s *Server) runPipeline(
+	ctx context.Context,
+	run *agentRun,
+	pipeline *grimoire.Entry,
+	userContext string,
+) agentResult {
+	slog.DebugContext(ctx, "executing pipeline",
+		slog.String("name", pipeline.Name), slog.Int("steps", len(pipeline.Steps)))
+
+	start := time.Now()
+	result := agentResult{Name: pipeline.Name, Attempts: 1}
+	stepContext := userContext
+
+	for i, step := range pipeline.Steps {
+		agents := make([]*grimoire.Entry, 0, len(step.Agents))
+
+		for _, name := range step.Agents {
+			agent, err := s.store.Get(grimoire.TypeAgent, name)
+			if err != nil {
+				result.Error = fmt.Errorf("step %d: %w", i+1, err)
+				result.Duration = time.Since(start)
+
+				return result
+			}
+
+			agents = append(agents, agent)
+		}
+
+		results := s.runAgents(ctx, run, agents, stepContext)
+		result.Steps = append(result.Steps, results)
+
+		for _, r := range results {
+			if r.Error != nil {
+				result.Error = fmt.Errorf("step %d: %s: %w", i+1, r.Name, r.Error)
+				result.TimedOut = r.TimedOut
+				result.Duration = time.Since(start)
+
+				slog.WarnContext(ctx, "pipeline step failed",
+					slog.String("name", pipeline.Name), slog.Int("step", i+1), slog.String("agent", r.Name))
+
+				return result
+			}
+		}
+
+		result.Output = combineOutputs(results)
+		stepContext = joinContext(userContext, result.Output)
+	}
+
+	result.Duration = time.Since(start)
+
+	slog.DebugContext(ctx, "pipeline completed",
+		slog.String("name", pipeline.Name), slog.Duration("duration", result.Duration))
+
+	return result
+}
+
+// combineOutputs merges the outputs of a step. A single output is returned as is;
+// multiple outputs are placed under a heading per agent.
+func combineOutputs(results []agentResult) string {
+	if len(results) == 1 {
+		return results[0].Output
+	}
+
+	var b strings.Builder
+
+	for i, r := range results {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+
+		fmt.Fprintf(&b, "### %s\n\n%s", r.Name, r.Output)
+	}
+
+	return b.String()
+}
+
+// joinContext appends the previous step's output to the user-provided context.
+func joinContext(userContext, previous string) string {
+	if userContext == "" {
+		return "### Previous step output\n\n" + previous
+	}
+
+	return userContext + "\n\n### Previous step output\n\n" + previous
+}
+
+// writeSteps writes a one-line summary per pipeline step.
+func writeSteps(b *strings.Builder, steps [][]agentResult) {
+	b.WriteString("\n\n**Steps**:\n")
+
+	for i, results := range steps {
+		parts := make([]string, len(results))
+
+		for j, r := range results {
+			status := r.Duration.Round(time.Millisecond).String()
+			if r.Error != nil {
+				status = "failed"
+			}
+
+			parts[j] = fmt.Sprintf("%s (%s)", r.Name, status)
+		}
+
+		fmt.Fprintf(b, "%d. %s\n", i+1, strings.Join(parts, ", "))
+	}
+}
+
+// stepOutputs flattens pipeline step results into structured output.
+func stepOutputs(steps [][]agentResult) []agentStepOutput {
+	var outputs []agentStepOutput
+
+	for i, results := range steps {
+		for _, r := range results {
+			out := agentStepOutput{
+				Step:       i + 1,
+				Name:       r.Name,
+				Output:     r.Output,
+				Data:       r.Data,
+				DurationMS: r.Duration.Milliseconds(),
+				TimedOut:   r.TimedOut,
+			}
+
+			if r.Error != nil {
+				out.Error = r.Error.Error()
+			}
+
+			outputs = append(outputs, out)
+		}
+	}
+
+	return outputs
+}
@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// progressReporter sends progress notifications for a tool call.
+// It does nothing if the request did not include a progress token.
+// Safe for concurrent use; progress increases by one per step.
+type progressReporter struct {
+	session *mcp.ServerSession
+	token   any
+	total   float64
+
+	mu       sync.Mutex
+	progress float64
+}
+
+// newProgressReporter creates a reporter for a request expected to take total steps.
+func newProgressReporter(req *mcp.CallToolRequest, total int) *progressReporter {
+	return &progressReporter{
+		session: req.Session,
+		token:   req.Params.GetProgressToken(),
+		total:   float64(total),
+	}
+}
+
+// step records one unit of progress and notifies the client with message.
+func (p *progressReporter) step(ctx context.Context, message string) {
+	if p.token == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.progress++
+
+	err := p.session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: p.token,
+		Message:       message,
+		Progress:      p.progress,
+		Total:         p.total,
+	})
+	if err != nil {
+		slog.DebugContext(ctx, "failed to send progress notification", slog.Any("error", err))
+	}
+}
@@ -2,22 +2,34 @@ package mcp
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/monke/grimoire/internal/grimoire"
 )
 
+// registerPrompts registers a prompt per skill, and one per alias so clients
+// requesting a skill by an old name still get it.
 func (s *Server) registerPrompts() {
 	skills := s.store.List(grimoire.TypeSkill)
 
 	for _, skill := range skills {
 		s.mcp.AddPrompt(&mcp.Prompt{
 			Name:        skill.Name,
-			Description: skill.Description,
+			Description: grimoire.BuildPromptDescription(skill),
 			Arguments:   convertArguments(skill.Arguments),
 		}, s.makePromptHandler(skill))
+
+		for _, alias := range skill.Aliases {
+			s.mcp.AddPrompt(&mcp.Prompt{
+				Name:        alias,
+				Description: fmt.Sprintf("Alias of %s: %s", skill.Name, grimoire.BuildPromptDescription(skill)),
+				Arguments:   convertArguments(skill.Arguments),
+			}, s.makePromptHandler(skill))
+		}
 	}
 
 	slog.Debug("prompts registered", slog.Int("count", len(skills)))
@@ -33,7 +45,11 @@ func convertArguments(args []grimoire.Argument) []*mcp.PromptArgument {
 		result[i] = &mcp.PromptArgument{
 			Name:        arg.Name,
 			Description: arg.Description,
-			Required:    arg.Required,
+			Required:    arg.NeedsValue(),
+		}
+
+		if hint := arg.Hint(); hint != "" {
+			result[i].Description = strings.TrimSpace(fmt.Sprintf("%s (%s)", arg.Description, hint))
 		}
 	}
 
@@ -46,10 +62,22 @@ func (s *Server) makePromptHandler(entry *grimoire.Entry) mcp.PromptHandler {
 			slog.String("name", entry.Name),
 			slog.Any("arguments", req.Params.Arguments))
 
-		body := entry.RenderBody(req.Params.Arguments)
+		values, err := s.resolveArguments(ctx, req.Session, entry, req.Params.Arguments)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := entry.RenderBody(values)
+		if err != nil {
+			return nil, fmt.Errorf("prompt %q: %w", entry.Name, err)
+		}
+
+		warnDeprecated(ctx, entry)
+
+		body = entry.DeprecationNotice() + body + grimoire.BuildDelegationSection(s.store, entry)
 
 		return &mcp.GetPromptResult{
-			Description: entry.Description,
+			Description: grimoire.BuildPromptDescription(entry),
 			Messages: []*mcp.PromptMessage{
 				{
 					Role:    "user",
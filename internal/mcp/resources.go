@@ -12,12 +12,23 @@ import (
 	"github.com/monke/grimoire/internal/grimoire"
 )
 
+const (
+	ruleURITemplate  = "grimoire://rules/{name}"
+	skillURITemplate = "grimoire://skills/{name}"
+)
+
+// resourceTemplateTypes maps each resource template to the entry type it serves.
+var resourceTemplateTypes = map[string]grimoire.Type{
+	ruleURITemplate:  grimoire.TypeRule,
+	skillURITemplate: grimoire.TypeSkill,
+}
+
 func (s *Server) registerResources() {
 	s.mcp.AddResourceTemplate(
 		&mcp.ResourceTemplate{
 			Name:        "rule",
 			Description: "Get a rule by name",
-			URITemplate: "grimoire://rules/{name}",
+			URITemplate: ruleURITemplate,
 			MIMEType:    "text/markdown",
 		},
 		s.handleRuleResource,
@@ -27,7 +38,7 @@ func (s *Server) registerResources() {
 		&mcp.ResourceTemplate{
 			Name:        "skill",
 			Description: "Get a skill by name",
-			URITemplate: "grimoire://skills/{name}",
+			URITemplate: skillURITemplate,
 			MIMEType:    "text/markdown",
 		},
 		s.handleSkillResource,
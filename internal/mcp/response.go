@@ -14,18 +14,31 @@ import (
 // entrySummary is a lightweight representation of an entry for tool result output.
 // Used by search and suggest tools to return concise entry information.
 type entrySummary struct {
-	Name        string `json:"name"`
-	Type        string `json:"type"`
-	Description string `json:"description,omitempty"`
+	Name        string   `json:"name"                  jsonschema:"Entry name"`
+	Type        string   `json:"type"                  jsonschema:"Entry type (rule, skill, instruction, agent)"`
+	Description string   `json:"description,omitempty" jsonschema:"Entry description"`
+	Tags        []string `json:"tags,omitempty"        jsonschema:"Entry tags"`
+	Severity    string   `json:"severity,omitempty"    jsonschema:"Rule severity (must, should, may)"`
 }
 
-func (s *Server) entrySummaryResult(ctx context.Context, entries []*grimoire.Entry) *mcp.CallToolResult {
+// entryListOutput is the structured output of the search and suggest tools.
+type entryListOutput struct {
+	Entries []entrySummary `json:"entries,omitempty" jsonschema:"Matching entries"`
+}
+
+// entrySummaryResult renders entries as a JSON text block and as structured output.
+func (s *Server) entrySummaryResult(
+	ctx context.Context,
+	entries []*grimoire.Entry,
+) (*mcp.CallToolResult, *entryListOutput) {
 	summaries := make([]entrySummary, len(entries))
 	for i, e := range entries {
 		summaries[i] = entrySummary{
 			Name:        e.Name,
 			Type:        string(e.Type),
 			Description: e.Description,
+			Tags:        e.Tags,
+			Severity:    string(e.Severity),
 		}
 	}
 
@@ -33,14 +46,14 @@ func (s *Server) entrySummaryResult(ctx context.Context, entries []*grimoire.Ent
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to marshal entry summaries", slog.Any("error", err))
 
-		return errorResult(err)
+		return errorResult(err), nil
 	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: string(data)},
 		},
-	}
+	}, &entryListOutput{Entries: summaries}
 }
 
 func (s *Server) getResourceContents(
@@ -56,17 +69,39 @@ func (s *Server) getResourceContents(
 		return nil, fmt.Errorf("get %s %q: %w", typ, name, err)
 	}
 
+	warnDeprecated(ctx, entry)
+
+	body, err := entry.RenderBody(nil)
+	if err != nil {
+		slog.WarnContext(ctx, "resource render failed",
+			slog.String("type", string(typ)), slog.String("name", name), slog.Any("error", err))
+
+		return nil, fmt.Errorf("rendering %s %q: %w", typ, name, err)
+	}
+
 	return &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{
 			{
 				URI:      uri,
 				MIMEType: "text/markdown",
-				Text:     entry.Body,
+				Text:     entry.DeprecationNotice() + body,
 			},
 		},
 	}, nil
 }
 
+// warnDeprecated logs a warning when a deprecated entry is loaded.
+func warnDeprecated(ctx context.Context, entry *grimoire.Entry) {
+	if !entry.Deprecated {
+		return
+	}
+
+	slog.WarnContext(ctx, "deprecated entry loaded",
+		slog.String("type", string(entry.Type)),
+		slog.String("name", entry.Name),
+		slog.String("replaced_by", entry.ReplacedBy))
+}
+
 func errorResult(err error) *mcp.CallToolResult {
 	return errorResultMsg(err.Error())
 }
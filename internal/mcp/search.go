@@ -5,17 +5,23 @@ import (
 	"log/slog"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/monke/grimoire/internal/grimoire"
 )
 
 // searchInput is the input for the search tool.
 type searchInput struct {
-	Query string `json:"query" jsonschema:"Search query"`
+	Query string   `json:"query,omitempty" jsonschema:"Search query"`
+	Tags  []string `json:"tags,omitempty"  jsonschema:"Only return entries with all of these tags"`
 }
 
 func (s *Server) registerSearch() {
 	mcp.AddTool(s.mcp, &mcp.Tool{
 		Name:        "search",
-		Description: "Search for guidance by keyword. Returns matching skills, rules, and prompts.",
+		Title:       "Search Guidance",
+		Annotations: readOnlyAnnotations("Search Guidance"),
+		Icons:       toolIcons("search"),
+		Description: "Search for guidance by keyword and/or tags. Returns matching skills, rules, and prompts.",
 	}, s.handleSearch)
 }
 
@@ -23,12 +29,14 @@ func (s *Server) handleSearch(
 	ctx context.Context,
 	_ *mcp.CallToolRequest,
 	input searchInput,
-) (*mcp.CallToolResult, any, error) {
-	slog.DebugContext(ctx, "searching", slog.String("query", input.Query))
+) (*mcp.CallToolResult, *entryListOutput, error) {
+	slog.DebugContext(ctx, "searching", slog.String("query", input.Query), slog.Any("tags", input.Tags))
 
-	entries := s.store.Search(input.Query)
+	entries := grimoire.FilterByTags(s.store.Search(input.Query), input.Tags)
 
 	slog.DebugContext(ctx, "search completed", slog.String("query", input.Query), slog.Int("results", len(entries)))
 
-	return s.entrySummaryResult(ctx, entries), nil, nil
+	result, output := s.entrySummaryResult(ctx, entries)
+
+	return result, output, nil
 }
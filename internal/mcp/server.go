@@ -14,23 +14,40 @@ import (
 type Server struct {
 	mcp   *mcp.Server
 	store *grimoire.Store
+	cfg   *grimoire.Config
+
+	// local runs agents on an OpenAI-compatible endpoint; nil if not configured.
+	local executor
+
+	// logLevels holds the logging levels set by connected sessions.
+	logLevels *logLevels
 }
 
 // New creates a new grimoire MCP server.
-func New(version string, s *grimoire.Store) *Server {
+func New(version string, s *grimoire.Store, cfg *grimoire.Config) *Server {
 	srv := &Server{
-		store: s,
-		mcp: mcp.NewServer(
-			&mcp.Implementation{
-				Name:    "grimoire",
-				Version: version,
-			},
-			&mcp.ServerOptions{
-				Instructions: grimoire.BuildServerInstructions(s),
-			},
-		),
+		store:     s,
+		cfg:       cfg,
+		logLevels: &logLevels{levels: make(map[*mcp.ServerSession]slog.Level)},
+	}
+
+	if cfg.Execution.Local != nil {
+		srv.local = newLocalExecutor(cfg.Execution.Local)
 	}
 
+	srv.mcp = mcp.NewServer(
+		&mcp.Implementation{
+			Name:    "grimoire",
+			Version: version,
+		},
+		&mcp.ServerOptions{
+			Instructions:      grimoire.BuildServerInstructions(s),
+			CompletionHandler: srv.handleComplete,
+		},
+	)
+
+	srv.mcp.AddReceivingMiddleware(srv.logLevels.middleware)
+
 	srv.registerGuidance()
 	srv.registerSearch()
 	srv.registerSuggest()
@@ -0,0 +1,200 @@
+package mcp
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/monke/grimoire/internal/grimoire"
+)
+
+var errInvalidOutput = errors.New("invalid structured output")
+
+// severityRank orders findings by severity, most severe first.
+// Unknown severities sort after all known ones.
+var severityRank = map[string]int{
+	"critical": 0,
+	"high":     1,
+	"medium":   2,
+	"low":      3,
+	"info":     4,
+}
+
+// outputInstructions appends the agent's output schema to its prompt.
+// Agents without an output schema get the prompt unchanged.
+func outputInstructions(agent *grimoire.Entry, prompt string) string {
+	if agent.OutputSchema == nil {
+		return prompt
+	}
+
+	schema, err := json.MarshalIndent(agent.OutputSchema, "", "  ")
+	if err != nil {
+		return prompt
+	}
+
+	return prompt + "\n\n## Output format\n\n" +
+		"Respond with a single JSON value matching this schema and nothing else:\n\n" +
+		"```json\n" + string(schema) + "\n```"
+}
+
+// structuredResponse parses a sampled response against the agent's output schema.
+// If the response is invalid, the model is shown the error and asked once to correct it.
+func (s *Server) structuredResponse(
+	ctx context.Context,
+	exec executor,
+	agent *grimoire.Entry,
+	messages []*mcp.SamplingMessage,
+	text string,
+) (agentResponse, error) {
+	schema := agent.ResolvedOutputSchema()
+
+	response, err := parseOutput(text, schema)
+	if err == nil {
+		return response, nil
+	}
+
+	slog.WarnContext(ctx, "agent returned invalid output, requesting correction",
+		slog.String("name", agent.Name), slog.Any("error", err))
+
+	messages = append(messages[:len(messages):len(messages)],
+		&mcp.SamplingMessage{Role: "assistant", Content: &mcp.TextContent{Text: text}},
+		&mcp.SamplingMessage{Role: "user", Content: &mcp.TextContent{
+			Text: "Your response did not match the required output format: " + err.Error() +
+				"\n\nRespond again with only the corrected JSON.",
+		}},
+	)
+
+	content, err := exec.execute(ctx, agent, messages)
+	if err != nil {
+		return agentResponse{}, err
+	}
+
+	corrected, ok := content.(*mcp.TextContent)
+	if !ok {
+		return agentResponse{}, fmt.Errorf("%w: expected JSON text", errInvalidOutput)
+	}
+
+	response, err = parseOutput(corrected.Text, schema)
+	if err != nil {
+		return agentResponse{Text: corrected.Text}, err
+	}
+
+	response.Transcript = append(messages, &mcp.SamplingMessage{Role: "assistant", Content: corrected})
+
+	return response, nil
+}
+
+// parseOutput decodes a response as JSON and validates it against schema.
+// A surrounding markdown code fence is ignored. The returned text is the
+// decoded value re-encoded with indentation.
+func parseOutput(text string, schema *jsonschema.Resolved) (agentResponse, error) {
+	var data any
+
+	err := json.Unmarshal([]byte(stripCodeFence(text)), &data)
+	if err != nil {
+		return agentResponse{}, fmt.Errorf("%w: %w", errInvalidOutput, err)
+	}
+
+	err = schema.Validate(data)
+	if err != nil {
+		return agentResponse{}, fmt.Errorf("%w: %w", errInvalidOutput, err)
+	}
+
+	formatted, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return agentResponse{}, fmt.Errorf("%w: %w", errInvalidOutput, err)
+	}
+
+	return agentResponse{Text: string(formatted), Data: data}, nil
+}
+
+// stripCodeFence removes a markdown code fence wrapping the whole text, if any.
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+
+	if !strings.HasPrefix(text, "```") || !strings.HasSuffix(text, "```") {
+		return text
+	}
+
+	text = strings.TrimSuffix(text, "```")
+
+	_, body, found := strings.Cut(text, "\n")
+	if !found {
+		return ""
+	}
+
+	return strings.TrimSpace(body)
+}
+
+// collectFindings merges the findings of all agents, including pipeline steps,
+// into one list sorted by severity. Each finding is tagged with its agent's name.
+func collectFindings(results []agentResult) []map[string]any {
+	var findings []map[string]any
+
+	var collect func(results []agentResult)
+
+	collect = func(results []agentResult) {
+		for _, r := range results {
+			for _, finding := range findingsOf(r.Data) {
+				finding = maps.Clone(finding)
+				finding["agent"] = r.Name
+				findings = append(findings, finding)
+			}
+
+			for _, step := range r.Steps {
+				collect(step)
+			}
+		}
+	}
+
+	collect(results)
+
+	slices.SortStableFunc(findings, func(a, b map[string]any) int {
+		return cmp.Compare(findingRank(a), findingRank(b))
+	})
+
+	return findings
+}
+
+// findingsOf extracts findings from structured agent output: either a
+// top-level array of objects or an object with a "findings" array.
+func findingsOf(data any) []map[string]any {
+	if obj, ok := data.(map[string]any); ok {
+		data = obj["findings"]
+	}
+
+	items, ok := data.([]any)
+	if !ok {
+		return nil
+	}
+
+	var findings []map[string]any
+
+	for _, item := range items {
+		if finding, ok := item.(map[string]any); ok {
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
+
+func findingRank(finding map[string]any) int {
+	severity, _ := finding["severity"].(string)
+
+	rank, ok := severityRank[strings.ToLower(severity)]
+	if !ok {
+		return len(severityRank)
+	}
+
+	return rank
+}
@@ -5,22 +5,37 @@ import (
 	"log/slog"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/monke/grimoire/internal/grimoire"
 )
 
 type suggestInput struct {
 	Task   string   `json:"task,omitempty"   jsonschema:"Task description to find matching skills"`
 	Files  []string `json:"files,omitempty"  jsonschema:"File paths to match against rule globs"`
 	Topics []string `json:"topics,omitempty" jsonschema:"Keywords to match against rule descriptions"`
+	Tags   []string `json:"tags,omitempty"   jsonschema:"Only suggest entries with all of these tags"`
+	Sort   string   `json:"sort,omitempty"   jsonschema:"Result order: name (default) or severity, most strict rules first"`
 }
 
+const (
+	sortByName     = "name"
+	sortBySeverity = "severity"
+)
+
 func (s *Server) registerSuggest() {
 	mcp.AddTool(s.mcp, &mcp.Tool{
-		Name: "suggest",
+		Name:        "suggest",
+		Title:       "Suggest Guidance",
+		Annotations: readOnlyAnnotations("Suggest Guidance"),
+		Icons:       toolIcons("suggest"),
 		Description: `Suggest relevant guidance based on context.
 
 - task: Find skills by task description (e.g., "commit", "review code")
 - files: Find rules by file patterns (e.g., ["main.go"])
 - topics: Find rules by keywords in description (e.g., ["error-handling"])
+- tags: Narrow the suggestions above to entries with all of these tags, or
+  on their own, find entries by tag (e.g., ["security"])
+- sort: "severity" lists must rules first, then should and may
 
 Returns matching entries. Use the guidance tool to load full content.`,
 	}, s.handleSuggest)
@@ -30,38 +45,46 @@ func (s *Server) handleSuggest(
 	ctx context.Context,
 	_ *mcp.CallToolRequest,
 	input suggestInput,
-) (*mcp.CallToolResult, any, error) {
+) (*mcp.CallToolResult, *entryListOutput, error) {
 	slog.DebugContext(ctx, "suggesting guidance",
 		slog.String("task", input.Task),
 		slog.Any("files", input.Files),
-		slog.Any("topics", input.Topics))
-
-	if input.Task != "" {
-		entries := s.store.FindByTask(input.Task)
+		slog.Any("topics", input.Topics),
+		slog.Any("tags", input.Tags))
 
-		slog.DebugContext(ctx, "task-based suggestion completed",
-			slog.Int("results", len(entries)))
-
-		return s.entrySummaryResult(ctx, entries), nil, nil
+	if input.Sort != "" && input.Sort != sortByName && input.Sort != sortBySeverity {
+		return errorResultMsg("sort must be name or severity"), nil, nil
 	}
 
-	if len(input.Files) > 0 {
-		entries := s.store.FindByGlobs(input.Files)
+	var (
+		entries []*grimoire.Entry
+		by      string
+	)
+
+	switch {
+	case input.Task != "":
+		entries, by = s.store.FindByTask(input.Task), "task"
+	case len(input.Files) > 0:
+		entries, by = s.store.FindByGlobs(input.Files), "files"
+	case len(input.Topics) > 0:
+		entries, by = s.store.FindByTopics(input.Topics), "topics"
+	case len(input.Tags) > 0:
+		entries, by = s.store.FindByTags(input.Tags), "tags"
+	default:
+		return errorResultMsg("provide task, files, topics, or tags parameter"), nil, nil
+	}
 
-		slog.DebugContext(ctx, "file-based suggestion completed",
-			slog.Int("results", len(entries)))
+	entries = grimoire.FilterByTags(entries, input.Tags)
 
-		return s.entrySummaryResult(ctx, entries), nil, nil
+	if input.Sort == sortBySeverity {
+		grimoire.SortBySeverity(entries)
 	}
 
-	if len(input.Topics) > 0 {
-		entries := s.store.FindByTopics(input.Topics)
+	slog.DebugContext(ctx, "suggestion completed",
+		slog.String("by", by),
+		slog.Int("results", len(entries)))
 
-		slog.DebugContext(ctx, "topic-based suggestion completed",
-			slog.Int("results", len(entries)))
-
-		return s.entrySummaryResult(ctx, entries), nil, nil
-	}
+	result, output := s.entrySummaryResult(ctx, entries)
 
-	return errorResultMsg("provide task, files, or topics parameter"), nil, nil
+	return result, output, nil
 }
@@ -0,0 +1,299 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/monke/grimoire/internal/grimoire"
+)
+
+const (
+	guidanceRequest = "@guidance "
+	agentRequest    = "@agent "
+
+	// maxTurnRequests limits the requests fulfilled in a single turn.
+	maxTurnRequests = 8
+)
+
+var errNotRequestable = errors.New("agent cannot be requested")
+
+// turnRequest is a request for guidance or an agent made by a multi-turn agent.
+type turnRequest struct {
+	typ  grimoire.Type
+	name string
+}
+
+// transcriptEntry is one message of an agent conversation.
+type transcriptEntry struct {
+	Role      string `json:"role"                 jsonschema:"Message role (user or assistant)"`
+	Text      string `json:"text,omitempty"       jsonschema:"Message text"`
+	MediaType string `json:"media_type,omitempty" jsonschema:"MIME type of image or audio content"`
+}
+
+// turnInstructions explains to multi-turn agents how to request guidance and
+// other agents. Single-turn agents get the prompt unchanged.
+func (s *Server) turnInstructions(agent *grimoire.Entry, prompt string) string {
+	if agent.MaxTurns <= 1 {
+		return prompt
+	}
+
+	var guidance, agents []string
+
+	for _, typ := range []grimoire.Type{grimoire.TypeRule, grimoire.TypeSkill} {
+		for _, entry := range s.store.ListCurrent(typ) {
+			guidance = append(guidance, entry.Name)
+		}
+	}
+
+	for _, entry := range s.store.ListCurrent(grimoire.TypeAgent) {
+		if entry.Name != agent.Name && !entry.IsPipeline() {
+			agents = append(agents, entry.Name)
+		}
+	}
+
+	var b strings.Builder
+
+	b.WriteString(prompt)
+	b.WriteString("\n\n## Requests\n\n")
+	b.WriteString("Before answering, you can ask for more information by adding request lines to your reply:\n\n")
+	b.WriteString("- `@guidance <name>` loads a rule or skill\n")
+	b.WriteString("- `@agent <name>` runs another agent, with the rest of your reply as its context\n\n")
+	fmt.Fprintf(&b, "Results are sent back to you. You have %d turns in total; ", agent.MaxTurns)
+	b.WriteString("reply without request lines to give your final answer.\n")
+
+	if len(guidance) > 0 {
+		fmt.Fprintf(&b, "\nGuidance: %s\n", strings.Join(guidance, ", "))
+	}
+
+	if len(agents) > 0 {
+		fmt.Fprintf(&b, "\nAgents: %s\n", strings.Join(agents, ", "))
+	}
+
+	return b.String()
+}
+
+// converse continues a multi-turn conversation while the agent's replies contain
+// requests, fulfilling them and sending the results back, up to the agent's turn
+// limit. Returns the final reply and the conversation before it.
+func (s *Server) converse(
+	ctx context.Context,
+	run *agentRun,
+	exec executor,
+	agent *grimoire.Entry,
+	messages []*mcp.SamplingMessage,
+	reply mcp.Content,
+) (mcp.Content, []*mcp.SamplingMessage, error) {
+	for turn := 2; turn <= agent.MaxTurns; turn++ {
+		text, ok := reply.(*mcp.TextContent)
+		if !ok {
+			return reply, messages, nil
+		}
+
+		requests, requestContext := parseRequests(text.Text)
+		if len(requests) == 0 {
+			return reply, messages, nil
+		}
+
+		slog.DebugContext(ctx, "agent made requests",
+			slog.String("name", agent.Name), slog.Int("turn", turn-1), slog.Int("count", len(requests)))
+
+		results := s.fulfillRequests(ctx, run, agent, requests, requestContext)
+		if turn == agent.MaxTurns {
+			results += "\n\nThis is your last turn: give your final answer without requests."
+		}
+
+		messages = append(messages[:len(messages):len(messages)],
+			&mcp.SamplingMessage{Role: "assistant", Content: reply},
+			&mcp.SamplingMessage{Role: "user", Content: &mcp.TextContent{Text: results}},
+		)
+
+		var err error
+
+		reply, err = exec.execute(ctx, agent, messages)
+		if err != nil {
+			return nil, messages, err
+		}
+	}
+
+	return reply, messages, nil
+}
+
+// parseRequests extracts request lines from a reply. The remaining text is
+// returned as context for requested agents.
+func parseRequests(text string) ([]turnRequest, string) {
+	var (
+		requests []turnRequest
+		rest     []string
+	)
+
+	for line := range strings.Lines(text) {
+		trimmed := strings.TrimSpace(line)
+
+		if name, ok := strings.CutPrefix(trimmed, guidanceRequest); ok {
+			requests = append(requests, turnRequest{typ: grimoire.TypeRule, name: strings.TrimSpace(name)})
+
+			continue
+		}
+
+		if name, ok := strings.CutPrefix(trimmed, agentRequest); ok {
+			requests = append(requests, turnRequest{typ: grimoire.TypeAgent, name: strings.TrimSpace(name)})
+
+			continue
+		}
+
+		rest = append(rest, line)
+	}
+
+	return requests, strings.TrimSpace(strings.Join(rest, ""))
+}
+
+// fulfillRequests loads requested guidance and runs requested agents, and
+// formats their results as the next user message. Failures are reported to the
+// model rather than ending the conversation.
+func (s *Server) fulfillRequests(
+	ctx context.Context,
+	run *agentRun,
+	agent *grimoire.Entry,
+	requests []turnRequest,
+	requestContext string,
+) string {
+	var b strings.Builder
+
+	for i, req := range requests {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+
+		if i == maxTurnRequests {
+			fmt.Fprintf(&b, "Skipped %d requests: at most %d are fulfilled per turn.", len(requests)-i, maxTurnRequests)
+
+			break
+		}
+
+		var (
+			text string
+			err  error
+		)
+
+		if req.typ == grimoire.TypeAgent {
+			fmt.Fprintf(&b, "## Agent: %s\n\n", req.name)
+			text, err = s.runSubAgent(ctx, run, agent, req.name, requestContext)
+		} else {
+			fmt.Fprintf(&b, "## Guidance: %s\n\n", req.name)
+			text, err = s.loadGuidance(ctx, run, req.name)
+		}
+
+		if err != nil {
+			slog.DebugContext(ctx, "agent request failed",
+				slog.String("name", agent.Name), slog.String("request", req.name), slog.Any("error", err))
+
+			text = "Error: " + err.Error()
+		}
+
+		b.WriteString(text)
+	}
+
+	return b.String()
+}
+
+// loadGuidance returns the rendered body of a skill or rule. Arguments are
+// resolved as for the guidance tool, from the values given for the entry in
+// the tool call.
+func (s *Server) loadGuidance(ctx context.Context, run *agentRun, name string) (string, error) {
+	entry, err := s.store.Get(grimoire.TypeSkill, name)
+	if err != nil {
+		entry, err = s.store.Get(grimoire.TypeRule, name)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("guidance: %w", err)
+	}
+
+	body, err := s.renderGuidance(ctx, run.session, entry, run.arguments[name])
+	if err != nil {
+		return "", fmt.Errorf("guidance: %w", err)
+	}
+
+	return body, nil
+}
+
+// runSubAgent runs an agent requested by another agent for a single turn,
+// sharing the caller's attempt deadline and concurrency slot. Argument values
+// given for the agent in the tool call are used.
+func (s *Server) runSubAgent(
+	ctx context.Context,
+	run *agentRun,
+	caller *grimoire.Entry,
+	name, requestContext string,
+) (string, error) {
+	agent, err := s.store.Get(grimoire.TypeAgent, name)
+	if err != nil {
+		return "", fmt.Errorf("agent: %w", err)
+	}
+
+	if agent.Name == caller.Name || agent.IsPipeline() {
+		return "", fmt.Errorf("%w: %q", errNotRequestable, agent.Name)
+	}
+
+	values := run.arguments[agent.Name]
+
+	if missing := agent.MissingArguments(values); len(missing) > 0 {
+		return "", fmt.Errorf("agent %q: %w: %s", agent.Name, grimoire.ErrMissingArguments, strings.Join(missing, ", "))
+	}
+
+	err = agent.ValidateArguments(values)
+	if err != nil {
+		return "", fmt.Errorf("validating arguments: %w", err)
+	}
+
+	exec, err := s.executorFor(run, agent)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := agent.RenderBody(values)
+	if err != nil {
+		return "", fmt.Errorf("prompt: %w", err)
+	}
+
+	prompt := outputInstructions(agent, agentPrompt(body, requestContext))
+	messages := s.agentMessages(run, prompt)
+
+	content, err := exec.execute(ctx, agent, messages)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := s.toResponse(ctx, exec, agent, messages, content)
+	if err != nil {
+		return "", err
+	}
+
+	if response.Media != nil {
+		return "", fmt.Errorf("%w: %s", errUnexpectedContentType, mediaType(response.Media))
+	}
+
+	return response.Text, nil
+}
+
+// transcript converts conversation messages into their serializable form.
+func transcript(messages []*mcp.SamplingMessage) []transcriptEntry {
+	entries := make([]transcriptEntry, len(messages))
+
+	for i, msg := range messages {
+		entries[i] = transcriptEntry{Role: string(msg.Role)}
+
+		if tc, ok := msg.Content.(*mcp.TextContent); ok {
+			entries[i].Text = tc.Text
+		} else {
+			entries[i].MediaType = mediaType(msg.Content)
+		}
+	}
+
+	return entries
+}